@@ -0,0 +1,115 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package app
+
+import (
+	"errors"
+
+	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+)
+
+// Process exit codes reported by kar. They are part of the public contract
+// documented in docs/references/cli.md, so existing values must not change.
+const (
+	// ExitSuccess indicates that the runner completed successfully.
+	ExitSuccess = 0
+	// ExitFailure indicates an unexpected failure that has no dedicated code.
+	ExitFailure = 1
+	// ExitInvalidInput indicates that a required option was empty, or that
+	// the template mapping, a resource override, an extra label or a pool was invalid.
+	ExitInvalidInput = 2
+	// ExitTemplateNotFound indicates that the virtual machine template doesn't exist.
+	ExitTemplateNotFound = 3
+	// ExitCreateFailure indicates that the runner resources couldn't be created.
+	ExitCreateFailure = 4
+	// ExitRunnerFailed indicates that the virtual machine instance ended in the Failed phase.
+	ExitRunnerFailed = 5
+	// ExitWaitTimeout indicates that the runner didn't complete within the wait timeout.
+	ExitWaitTimeout = 6
+	// ExitCleanupFailure indicates that the runner resources couldn't be deleted.
+	ExitCleanupFailure = 7
+	// ExitScheduleTimeout indicates that the virtual machine instance wasn't scheduled in time.
+	ExitScheduleTimeout = 8
+	// ExitBootTimeout indicates that the virtual machine instance didn't start running in time.
+	ExitBootTimeout = 9
+	// ExitReadyTimeout indicates that the virtual machine instance didn't become ready in time.
+	ExitReadyTimeout = 10
+	// ExitUnschedulable indicates that the virtual machine instance stayed unschedulable
+	// for longer than its grace period.
+	ExitUnschedulable = 11
+	// ExitDataVolumeFailed indicates that a runner data volume couldn't be imported, cloned or uploaded.
+	ExitDataVolumeFailed = 12
+	// ExitInterrupted indicates that kar was stopped by a signal before completion.
+	ExitInterrupted = 130
+)
+
+var (
+	// ErrCreateResources indicates that the runner resources creation step failed.
+	ErrCreateResources = errors.New("failed to create resources")
+
+	// ErrWaitResources indicates that waiting for the runner completion failed.
+	ErrWaitResources = errors.New("failed to wait for resources")
+
+	// ErrDeleteResources indicates that the runner resources deletion step failed.
+	ErrDeleteResources = errors.New("failed to delete resources")
+)
+
+// ExitCode maps an error returned by the root command to the process exit
+// code that describes its category.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitSuccess
+	case errors.Is(err, runner.ErrEmptyVMTemplate),
+		errors.Is(err, runner.ErrEmptyRunnerName),
+		errors.Is(err, runner.ErrEmptyJitConfig),
+		errors.Is(err, runner.ErrInvalidResourceOverride),
+		errors.Is(err, runner.ErrInvalidResourceMetadata),
+		errors.Is(err, ErrTemplateMapping),
+		errors.Is(err, ErrInvalidGCOutput),
+		errors.Is(err, ErrInvalidRenderOutput),
+		errors.Is(err, runner.ErrInvalidTemplateSource),
+		errors.Is(err, runner.ErrInvalidDiskRestoreStrategy),
+		errors.Is(err, runner.ErrInvalidPoolSpec),
+		errors.Is(err, runner.ErrPoolUnsupported):
+		return ExitInvalidInput
+	case errors.Is(err, runner.ErrTemplateNotFound):
+		return ExitTemplateNotFound
+	case errors.Is(err, runner.ErrRunnerFailed):
+		return ExitRunnerFailed
+	case errors.Is(err, runner.ErrWaitTimeout):
+		return ExitWaitTimeout
+	case errors.Is(err, runner.ErrScheduleTimeout):
+		return ExitScheduleTimeout
+	case errors.Is(err, runner.ErrBootTimeout):
+		return ExitBootTimeout
+	case errors.Is(err, runner.ErrReadyTimeout):
+		return ExitReadyTimeout
+	case errors.Is(err, runner.ErrUnschedulable):
+		return ExitUnschedulable
+	case errors.Is(err, runner.ErrDataVolumeFailed):
+		return ExitDataVolumeFailed
+	case errors.Is(err, ErrDeleteResources):
+		return ExitCleanupFailure
+	case errors.Is(err, ErrCreateResources):
+		return ExitCreateFailure
+	default:
+		return ExitFailure
+	}
+}
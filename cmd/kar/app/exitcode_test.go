@@ -0,0 +1,71 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package app_test
+
+import (
+	"fmt"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/cmd/kar/app"
+	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Exit Code", func() {
+	DescribeTable("categorizes root command errors", func(err error, expected int) {
+		Expect(app.ExitCode(err)).To(Equal(expected))
+	},
+		Entry("when there is no error", nil, app.ExitSuccess),
+		Entry("when the error is unknown", errExpectedFailure, app.ExitFailure),
+		Entry("when the vm template is empty",
+			fmt.Errorf("%w: %w", app.ErrCreateResources, runner.ErrEmptyVMTemplate), app.ExitInvalidInput),
+		Entry("when the runner name is empty",
+			fmt.Errorf("%w: %w", app.ErrCreateResources, runner.ErrEmptyRunnerName), app.ExitInvalidInput),
+		Entry("when the jit config is empty",
+			fmt.Errorf("%w: %w", app.ErrCreateResources, runner.ErrEmptyJitConfig), app.ExitInvalidInput),
+		Entry("when the template mapping is invalid", app.ErrTemplateMapping, app.ExitInvalidInput),
+		Entry("when an extra label is invalid",
+			fmt.Errorf("%w: %w", app.ErrCreateResources, runner.ErrInvalidResourceMetadata), app.ExitInvalidInput),
+		Entry("when the disk restore strategy is unknown",
+			fmt.Errorf("%w: %w", app.ErrCreateResources, runner.ErrInvalidDiskRestoreStrategy), app.ExitInvalidInput),
+		Entry("when the gc output format is invalid", app.ErrInvalidGCOutput, app.ExitInvalidInput),
+		Entry("when the vm template doesn't exist",
+			fmt.Errorf("%w: %w", app.ErrCreateResources, runner.ErrTemplateNotFound), app.ExitTemplateNotFound),
+		Entry("when the creation failed",
+			fmt.Errorf("%w: %w", app.ErrCreateResources, errExpectedFailure), app.ExitCreateFailure),
+		Entry("when the runner failed",
+			fmt.Errorf("%w: %w", app.ErrWaitResources, runner.ErrRunnerFailed), app.ExitRunnerFailed),
+		Entry("when the wait timed out",
+			fmt.Errorf("%w: %w", app.ErrWaitResources, runner.ErrWaitTimeout), app.ExitWaitTimeout),
+		Entry("when the vmi wasn't scheduled in time",
+			fmt.Errorf("%w: %w", app.ErrWaitResources, runner.ErrScheduleTimeout), app.ExitScheduleTimeout),
+		Entry("when the vmi stayed unschedulable",
+			fmt.Errorf("%w: %w", app.ErrWaitResources, runner.ErrUnschedulable), app.ExitUnschedulable),
+		Entry("when a data volume failed",
+			fmt.Errorf("%w: %w", app.ErrCreateResources, runner.ErrDataVolumeFailed), app.ExitDataVolumeFailed),
+		Entry("when the vmi didn't boot in time",
+			fmt.Errorf("%w: %w", app.ErrWaitResources, runner.ErrBootTimeout), app.ExitBootTimeout),
+		Entry("when the vmi wasn't ready in time",
+			fmt.Errorf("%w: %w", app.ErrWaitResources, runner.ErrReadyTimeout), app.ExitReadyTimeout),
+		Entry("when the wait failed for another reason",
+			fmt.Errorf("%w: %w", app.ErrWaitResources, errExpectedFailure), app.ExitFailure),
+		Entry("when the delete failed",
+			fmt.Errorf("%w: %w", app.ErrDeleteResources, errExpectedFailure), app.ExitCleanupFailure),
+	)
+})
@@ -35,6 +35,34 @@ func installFlags(flags *pflag.FlagSet, cmdOptions *Opts) {
 		"The name of the runner.")
 	flags.StringVarP(&cmdOptions.JitConfig, "actions-runner-input-jitconfig", "c", "",
 		"The opaque JIT runner config.")
+	flags.StringVar(&cmdOptions.TemplateMapping, "kubevirt-vm-template-mapping", "",
+		"The YAML file that maps runner labels and environment variables to VirtualMachine templates.")
+	flags.StringVar(&cmdOptions.TemplateSource, "template-source", "",
+		"Where the template is loaded from: virtualmachine, file, configmap, clustertemplate or oci.")
+	flags.StringSliceVar(&cmdOptions.RunnerLabels, "runner-labels", nil,
+		"The labels of the runner, matched against the template mapping rules.")
+	flags.Uint32Var(&cmdOptions.CPUCores, "cpu-cores", 0,
+		"The number of guest vCPUs, as a single socket of cores, overriding the template.")
+	flags.StringVar(&cmdOptions.Memory, "memory", "",
+		"The guest memory, overriding the template (e.g. 8Gi).")
+	flags.StringVar(&cmdOptions.DiskSize, "disk-size", "",
+		"The storage request of the boot disk DataVolumeTemplate, overriding the template (e.g. 50Gi).")
+	flags.StringVar(&cmdOptions.DiskRestoreStrategy, "disk-restore-strategy", "",
+		"How the disks are created from the snapshots referenced by the template: snapshot, clone or import.")
+	flags.StringVar(&cmdOptions.GitHubRepository, "github-repository", "",
+		"The GitHub repository of the job, recorded as a label on the runner resources.")
+	flags.StringVar(&cmdOptions.GitHubWorkflow, "github-workflow", "",
+		"The GitHub workflow of the job, recorded as a label on the runner resources.")
+	flags.StringVar(&cmdOptions.GitHubRunID, "github-run-id", "",
+		"The GitHub workflow run ID of the job, recorded as a label on the runner resources.")
+	flags.StringToStringVar(&cmdOptions.ExtraLabels, "extra-labels", nil,
+		"Extra labels added to the runner resources (e.g. team=infra,cost-center=ci).")
+	flags.StringToStringVar(&cmdOptions.ExtraAnnotations, "extra-annotations", nil,
+		"Extra annotations added to the runner resources (e.g. example.com/owner=infra).")
+	flags.DurationVar(&cmdOptions.PreserveOnFailure, "preserve-on-failure", 0,
+		"Keep the runner resources for this duration when the runner fails or times out (0 disables it).")
+	flags.StringVar(&cmdOptions.Pool, "pool", "",
+		"The warm pool to claim a pre-booted runner from, creating a new runner when none is available.")
 }
 
 func initializeConfig(cmd *cobra.Command) error {
@@ -0,0 +1,151 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	gcOutputText = "text"
+	gcOutputJSON = "json"
+)
+
+// ErrInvalidGCOutput indicates that the gc output format isn't supported.
+var ErrInvalidGCOutput = errors.New("invalid output format")
+
+// GCOpts stores all the options for configuring the gc command.
+type GCOpts struct {
+	DryRun bool
+	MaxAge time.Duration
+	Output string
+}
+
+// NewGCCommand returns the command that deletes the runner resources left
+// behind by runner pods that no longer exist.
+func NewGCCommand(ctx context.Context, collector runner.GarbageCollector) *cobra.Command {
+	var opts GCOpts
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Delete the runner resources left behind by runner pods that no longer exist",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runGC(ctx, cmd.OutOrStdout(), collector, opts)
+		},
+	}
+
+	installGCFlags(cmd.Flags(), &opts)
+
+	return cmd
+}
+
+// addGCCommand registers the gc subcommand when the runner is able to
+// collect garbage.
+func addGCCommand(ctx context.Context, root *cobra.Command, candidate runner.Runner) {
+	if collector, ok := candidate.(runner.GarbageCollector); ok {
+		root.AddCommand(NewGCCommand(ctx, collector))
+	}
+}
+
+func installGCFlags(flags *pflag.FlagSet, cmdOptions *GCOpts) {
+	flags.BoolVar(&cmdOptions.DryRun, "dry-run", false,
+		"Only report the orphaned runner resources without deleting them.")
+	flags.DurationVar(&cmdOptions.MaxAge, "max-age", 0,
+		"Also delete runner resources older than this duration, even if their runner pod exists (0 disables it).")
+	flags.StringVarP(&cmdOptions.Output, "output", "o", gcOutputText,
+		"The output format, either text or json.")
+}
+
+func runGC(ctx context.Context, out io.Writer, collector runner.GarbageCollector, opts GCOpts) error {
+	if opts.Output != gcOutputText && opts.Output != gcOutputJSON {
+		return fmt.Errorf("%w: %q", ErrInvalidGCOutput, opts.Output)
+	}
+
+	results, err := collector.CollectGarbage(ctx, runner.GCOptions{DryRun: opts.DryRun, MaxAge: opts.MaxAge})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDeleteResources, err)
+	}
+
+	if opts.Output == gcOutputJSON {
+		err = writeGCJSON(out, results)
+	} else {
+		err = writeGCText(out, results)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if result.Error != "" {
+			return fmt.Errorf("%w: %s %s: %s", ErrDeleteResources, result.Kind, result.Name, result.Error)
+		}
+	}
+
+	return nil
+}
+
+func writeGCJSON(out io.Writer, results []runner.GCResult) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(results)
+	if err != nil {
+		return fmt.Errorf("cannot encode gc results: %w", err)
+	}
+
+	return nil
+}
+
+func writeGCText(out io.Writer, results []runner.GCResult) error {
+	writer := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+
+	_, _ = fmt.Fprintln(writer, "KIND\tNAMESPACE\tNAME\tAGE\tREASON\tACTION")
+
+	for _, result := range results {
+		action := "would delete"
+
+		switch {
+		case result.Error != "":
+			action = "failed: " + result.Error
+		case result.Deleted:
+			action = "deleted"
+		}
+
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			result.Kind, result.Namespace, result.Name, result.Age, result.Reason, action)
+	}
+
+	err := writer.Flush()
+	if err != nil {
+		return fmt.Errorf("cannot write gc results: %w", err)
+	}
+
+	return nil
+}
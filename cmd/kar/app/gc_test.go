@@ -0,0 +1,112 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/cmd/kar/app"
+	runnerpkg "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+type collectorMock struct {
+	mock
+
+	results []runnerpkg.GCResult
+	err     error
+	opts    runnerpkg.GCOptions
+}
+
+func (c *collectorMock) CollectGarbage(_ context.Context, opts runnerpkg.GCOptions) ([]runnerpkg.GCResult, error) {
+	c.opts = opts
+
+	return c.results, c.err
+}
+
+var _ = Describe("GC command", func() {
+	var collector *collectorMock
+	var cmd *cobra.Command
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		collector = &collectorMock{
+			results: []runnerpkg.GCResult{{
+				Kind:      "VirtualMachineInstance",
+				Namespace: "default",
+				Name:      "runner-abc",
+				Age:       "2h0m0s",
+				Reason:    "runner pod default/runner-abc not found",
+				Deleted:   true,
+			}},
+		}
+		out = &bytes.Buffer{}
+		cmd = app.NewRootCommand(context.TODO(), collector, app.Opts{})
+		cmd.SetOut(out)
+	})
+
+	It("is only registered for runners that collect garbage", func() {
+		Expect(app.NewRootCommand(context.TODO(), &mock{}, app.Opts{}).Commands()).To(BeEmpty())
+		Expect(cmd.Commands()).To(HaveLen(1))
+	})
+
+	It("passes the dry run and max age options", func() {
+		cmd.SetArgs([]string{"gc", "--dry-run", "--max-age", "6h"})
+
+		Expect(cmd.Execute()).To(Succeed())
+		Expect(collector.opts).To(Equal(runnerpkg.GCOptions{DryRun: true, MaxAge: 6 * time.Hour}))
+		Expect(out.String()).To(ContainSubstring("runner-abc"))
+		Expect(out.String()).To(ContainSubstring("deleted"))
+	})
+
+	It("writes json output", func() {
+		cmd.SetArgs([]string{"gc", "--output", "json"})
+
+		Expect(cmd.Execute()).To(Succeed())
+
+		var results []runnerpkg.GCResult
+		Expect(json.Unmarshal(out.Bytes(), &results)).To(Succeed())
+		Expect(results).To(Equal(collector.results))
+	})
+
+	It("rejects unknown output formats", func() {
+		cmd.SetArgs([]string{"gc", "--output", "yaml"})
+		cmd.SilenceUsage = true
+
+		err := cmd.Execute()
+		Expect(err).To(MatchError(app.ErrInvalidGCOutput))
+		Expect(app.ExitCode(err)).To(Equal(app.ExitInvalidInput))
+	})
+
+	It("fails when a resource couldn't be deleted", func() {
+		collector.results[0].Deleted = false
+		collector.results[0].Error = "forbidden"
+		cmd.SetArgs([]string{"gc"})
+		cmd.SilenceUsage = true
+
+		err := cmd.Execute()
+		Expect(err).To(MatchError(app.ErrDeleteResources))
+		Expect(app.ExitCode(err)).To(Equal(app.ExitCleanupFailure))
+	})
+})
@@ -0,0 +1,149 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ErrTemplateMapping indicates that the template mapping file couldn't be
+// used to select the virtual machine template.
+var ErrTemplateMapping = errors.New("invalid template mapping")
+
+// TemplateTarget identifies the VirtualMachine used as the runner template.
+type TemplateTarget struct {
+	Template  string `json:"template"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// TemplateMatch lists the conditions a runner must satisfy for a rule to
+// apply. Every listed label must be present and every environment variable
+// must hold the given value.
+type TemplateMatch struct {
+	Labels []string          `json:"labels,omitempty"`
+	Env    map[string]string `json:"env,omitempty"`
+}
+
+// TemplateRule selects Target when Match is satisfied.
+type TemplateRule struct {
+	Match TemplateMatch `json:"match"`
+
+	TemplateTarget `json:",inline"`
+}
+
+// TemplateMapping is the content of the file passed through
+// --kubevirt-vm-template-mapping. Rules are evaluated in order and the first
+// matching one wins; Default applies when none matches.
+type TemplateMapping struct {
+	Default *TemplateTarget `json:"default,omitempty"`
+	Rules   []TemplateRule  `json:"rules,omitempty"`
+}
+
+// LoadTemplateMapping reads and validates the template mapping file.
+func LoadTemplateMapping(path string) (*TemplateMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTemplateMapping, err)
+	}
+
+	var mapping TemplateMapping
+
+	err = yaml.UnmarshalStrict(data, &mapping)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot parse %s: %w", ErrTemplateMapping, path, err)
+	}
+
+	for i, rule := range mapping.Rules {
+		if rule.Template == "" {
+			return nil, fmt.Errorf("%w: rule %d has no template", ErrTemplateMapping, i)
+		}
+	}
+
+	if mapping.Default != nil && mapping.Default.Template == "" {
+		return nil, fmt.Errorf("%w: default has no template", ErrTemplateMapping)
+	}
+
+	return &mapping, nil
+}
+
+// Resolve returns the target of the first rule matched by the runner labels
+// and environment, falling back to Default. It reports false when neither
+// applies.
+func (m *TemplateMapping) Resolve(
+	labels []string,
+	lookupEnv func(string) (string, bool),
+) (TemplateTarget, bool) {
+	for _, rule := range m.Rules {
+		if rule.Match.matches(labels, lookupEnv) {
+			return rule.TemplateTarget, true
+		}
+	}
+
+	if m.Default != nil {
+		return *m.Default, true
+	}
+
+	return TemplateTarget{}, false
+}
+
+func (tm TemplateMatch) matches(labels []string, lookupEnv func(string) (string, bool)) bool {
+	for _, label := range tm.Labels {
+		if !slices.Contains(labels, label) {
+			return false
+		}
+	}
+
+	for key, expected := range tm.Env {
+		if value, found := lookupEnv(key); !found || value != expected {
+			return false
+		}
+	}
+
+	return true
+}
+
+// resolveTemplate overrides the template options with the target selected by
+// the template mapping file, if one is configured.
+func resolveTemplate(opts Opts) (Opts, error) {
+	if opts.TemplateMapping == "" {
+		return opts, nil
+	}
+
+	mapping, err := LoadTemplateMapping(opts.TemplateMapping)
+	if err != nil {
+		return opts, err
+	}
+
+	target, found := mapping.Resolve(opts.RunnerLabels, os.LookupEnv)
+	if !found {
+		return opts, nil
+	}
+
+	opts.VMTemplate = target.Template
+	if target.Namespace != "" {
+		opts.VMTemplateNamespace = target.Namespace
+	}
+
+	return opts, nil
+}
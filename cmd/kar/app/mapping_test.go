@@ -0,0 +1,123 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/cmd/kar/app"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func writeTemplateMapping(content string) string {
+	path := filepath.Join(GinkgoT().TempDir(), "mapping.yaml")
+	Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+
+	return path
+}
+
+func lookupEnvFrom(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, found := env[key]
+
+		return value, found
+	}
+}
+
+var _ = Describe("Template Mapping", func() {
+	const mapping = `
+default:
+  template: vm-template
+  namespace: vms
+rules:
+  - match:
+      labels: [gpu]
+      env:
+        GITHUB_JOB: train
+    template: gpu-template
+    namespace: gpu-vms
+  - match:
+      labels: [large]
+    template: large-template
+  - match:
+      env:
+        GITHUB_WORKFLOW: nightly
+    template: nightly-template
+`
+
+	DescribeTable("resolves the vm template", func(labels []string, env map[string]string,
+		expected app.TemplateTarget,
+	) {
+		templateMapping, err := app.LoadTemplateMapping(writeTemplateMapping(mapping))
+		Expect(err).NotTo(HaveOccurred())
+
+		target, found := templateMapping.Resolve(labels, lookupEnvFrom(env))
+
+		Expect(found).To(BeTrue())
+		Expect(target).To(Equal(expected))
+	},
+		Entry("when every condition of a rule matches", []string{"linux", "gpu"},
+			map[string]string{"GITHUB_JOB": "train"},
+			app.TemplateTarget{Template: "gpu-template", Namespace: "gpu-vms"}),
+		Entry("when only part of a rule matches", []string{"gpu"}, map[string]string{"GITHUB_JOB": "lint"},
+			app.TemplateTarget{Template: "vm-template", Namespace: "vms"}),
+		Entry("when several rules match", []string{"gpu", "large"}, map[string]string{"GITHUB_JOB": "train"},
+			app.TemplateTarget{Template: "gpu-template", Namespace: "gpu-vms"}),
+		Entry("when a rule only matches the labels", []string{"large"}, nil,
+			app.TemplateTarget{Template: "large-template"}),
+		Entry("when a rule only matches the environment", nil, map[string]string{"GITHUB_WORKFLOW": "nightly"},
+			app.TemplateTarget{Template: "nightly-template"}),
+		Entry("when no rule matches", []string{"linux"}, nil,
+			app.TemplateTarget{Template: "vm-template", Namespace: "vms"}),
+	)
+
+	It("reports no target when no rule matches and there is no default", func() {
+		templateMapping := app.TemplateMapping{
+			Rules: []app.TemplateRule{
+				{
+					Match:          app.TemplateMatch{Labels: []string{"gpu"}},
+					TemplateTarget: app.TemplateTarget{Template: "gpu-template"},
+				},
+			},
+		}
+
+		_, found := templateMapping.Resolve([]string{"linux"}, lookupEnvFrom(nil))
+
+		Expect(found).To(BeFalse())
+	})
+
+	DescribeTable("rejects invalid mapping files", func(content string) {
+		_, err := app.LoadTemplateMapping(writeTemplateMapping(content))
+
+		Expect(err).To(MatchError(app.ErrTemplateMapping))
+	},
+		Entry("when the file isn't valid YAML", "rules: ["),
+		Entry("when the file has unknown fields", "templates: []"),
+		Entry("when a rule has no template", "rules:\n  - match:\n      labels: [gpu]\n"),
+		Entry("when the default has no template", "default:\n  namespace: vms\n"),
+	)
+
+	It("returns an error when the mapping file doesn't exist", func() {
+		_, err := app.LoadTemplateMapping(filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+
+		Expect(err).To(MatchError(app.ErrTemplateMapping))
+	})
+})
@@ -18,10 +18,26 @@ limitations under the License.
 
 package app
 
+import "time"
+
 // Opts stores all the options for configuring the root kar command.
 type Opts struct {
 	VMTemplate          string
 	VMTemplateNamespace string
 	RunnerName          string
 	JitConfig           string
+	TemplateMapping     string
+	RunnerLabels        []string
+	CPUCores            uint32
+	Memory              string
+	DiskSize            string
+	DiskRestoreStrategy string
+	TemplateSource      string
+	GitHubRepository    string
+	GitHubWorkflow      string
+	GitHubRunID         string
+	ExtraLabels         map[string]string
+	ExtraAnnotations    map[string]string
+	PreserveOnFailure   time.Duration
+	Pool                string
 }
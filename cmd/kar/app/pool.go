@@ -0,0 +1,195 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const defaultPoolInterval = 30 * time.Second
+
+// PoolOpts stores all the options for configuring the pool command.
+type PoolOpts struct {
+	Name                string
+	Size                int
+	Interval            time.Duration
+	VMTemplate          string
+	VMTemplateNamespace string
+	CPUCores            uint32
+	Memory              string
+	DiskSize            string
+	DiskRestoreStrategy string
+	TemplateSource      string
+	ExtraLabels         map[string]string
+	ExtraAnnotations    map[string]string
+}
+
+// NewPoolCommand returns the long-running command that keeps a pool of
+// pre-booted runners for the jobs to claim.
+func NewPoolCommand(ctx context.Context, pooler runner.Pooler) *cobra.Command {
+	var opts PoolOpts
+
+	cmd := &cobra.Command{
+		Use:   "pool",
+		Short: "Keep a pool of pre-booted runners that jobs claim with --pool",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runPool(ctx, pooler, opts)
+		},
+	}
+
+	installPoolFlags(cmd.Flags(), &opts)
+
+	return cmd
+}
+
+// addPoolCommand registers the pool subcommand when the runner is able to
+// keep warm pools.
+func addPoolCommand(ctx context.Context, root *cobra.Command, candidate runner.Runner) {
+	if pooler, ok := candidate.(runner.Pooler); ok {
+		root.AddCommand(NewPoolCommand(ctx, pooler))
+	}
+}
+
+func installPoolFlags(flags *pflag.FlagSet, cmdOptions *PoolOpts) {
+	flags.StringVar(&cmdOptions.Name, "pool", "",
+		"The name of the pool, referenced by the jobs with --pool.")
+	flags.IntVar(&cmdOptions.Size, "pool-size", 1,
+		"The number of warm runners to keep booted.")
+	flags.DurationVar(&cmdOptions.Interval, "pool-interval", defaultPoolInterval,
+		"The interval between two reconciliations of the pool.")
+	flags.StringVarP(&cmdOptions.VMTemplate, "kubevirt-vm-template", "t", "vm-template",
+		"The VirtualMachine resource to use as the template.")
+	flags.StringVarP(&cmdOptions.VMTemplateNamespace, "kubevirt-vm-template-namespace", "n", "default",
+		"The namespace where the VirtualMachine template resource exists.")
+	flags.StringVar(&cmdOptions.TemplateSource, "template-source", "",
+		"Where the template is loaded from: virtualmachine, file, configmap, clustertemplate or oci.")
+	flags.Uint32Var(&cmdOptions.CPUCores, "cpu-cores", 0,
+		"The number of guest vCPUs, as a single socket of cores, overriding the template.")
+	flags.StringVar(&cmdOptions.Memory, "memory", "",
+		"The guest memory, overriding the template (e.g. 8Gi).")
+	flags.StringVar(&cmdOptions.DiskSize, "disk-size", "",
+		"The storage request of the boot disk DataVolumeTemplate, overriding the template (e.g. 50Gi).")
+	flags.StringVar(&cmdOptions.DiskRestoreStrategy, "disk-restore-strategy", "",
+		"How the disks are created from the snapshots referenced by the template: snapshot, clone or import.")
+	flags.StringToStringVar(&cmdOptions.ExtraLabels, "extra-labels", nil,
+		"Extra labels added to the runner resources (e.g. team=infra,cost-center=ci).")
+	flags.StringToStringVar(&cmdOptions.ExtraAnnotations, "extra-annotations", nil,
+		"Extra annotations added to the runner resources (e.g. example.com/owner=infra).")
+}
+
+// runPool reconciles the pool every interval until ctx is done. Failed
+// reconciliations are retried on the next interval, unless the pool itself
+// is invalid.
+func runPool(ctx context.Context, pooler runner.Pooler, opts PoolOpts) error {
+	log := utils.GetLogger()
+
+	if opts.Interval <= 0 {
+		return fmt.Errorf("%w: interval %s isn't positive", runner.ErrInvalidPoolSpec, opts.Interval)
+	}
+
+	template, err := parseTemplateOptions(opts.TemplateSource, opts.DiskRestoreStrategy)
+	if err != nil {
+		return err
+	}
+
+	overrides, err := parseResourceOverrides(opts.CPUCores, opts.Memory, opts.DiskSize)
+	if err != nil {
+		return err
+	}
+
+	spec := runner.PoolSpec{
+		Name:              opts.Name,
+		Template:          opts.VMTemplate,
+		TemplateNamespace: opts.VMTemplateNamespace,
+		Size:              opts.Size,
+		TemplateOptions:   template,
+		Overrides:         overrides,
+		Metadata: runner.ResourceMetadata{
+			Labels:      opts.ExtraLabels,
+			Annotations: opts.ExtraAnnotations,
+		},
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		status, err := pooler.ReconcilePool(ctx, spec)
+
+		switch {
+		case isInvalidPool(err):
+			return err
+		case err != nil:
+			log.Warnf("failed to reconcile the %s pool: %v", opts.Name, err)
+		default:
+			log.Printf("Pool %s: %d warm (%d ready), %d claimed, %d created, %d deleted\n",
+				opts.Name, status.Warm, status.Ready, status.Claimed, status.Created, status.Deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func isInvalidPool(err error) bool {
+	return errors.Is(err, runner.ErrInvalidPoolSpec) ||
+		errors.Is(err, runner.ErrPoolUnsupported) ||
+		errors.Is(err, runner.ErrInvalidResourceOverride) ||
+		errors.Is(err, runner.ErrInvalidResourceMetadata) ||
+		errors.Is(err, runner.ErrInvalidDiskRestoreStrategy)
+}
+
+// createRunner claims a warm runner when a pool is configured, and creates a
+// new runner when the pool has none to offer. Warm runners are matched by
+// template name, whatever its source, and by the overrides and the disk
+// restore strategy of the pool.
+func createRunner(
+	ctx context.Context,
+	candidate runner.Runner,
+	opts Opts,
+	template runner.TemplateOptions,
+	overrides runner.ResourceOverrides,
+) error {
+	log := utils.GetLogger()
+	metadata := resourceMetadata(opts)
+
+	if pooler, ok := candidate.(runner.Pooler); ok && opts.Pool != "" {
+		err := pooler.ClaimRunner(ctx, opts.Pool, opts.VMTemplate, opts.VMTemplateNamespace,
+			opts.RunnerName, opts.JitConfig, template, overrides, metadata)
+		if !errors.Is(err, runner.ErrNoWarmRunner) {
+			return err
+		}
+
+		log.Printf("No warm runner available in the %s pool; creating a new one: %v\n", opts.Pool, err)
+	}
+
+	return candidate.CreateResources(ctx, opts.VMTemplate, opts.VMTemplateNamespace, opts.RunnerName,
+		opts.JitConfig, template, overrides, metadata)
+}
@@ -0,0 +1,173 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package app_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/cmd/kar/app"
+	runnerpkg "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type poolerMock struct {
+	mock
+
+	reconcileErr   error
+	claimErr       error
+	claimCalled    bool
+	claimTemplate  runnerpkg.TemplateOptions
+	claimOverrides runnerpkg.ResourceOverrides
+	reconciles     int
+	spec           runnerpkg.PoolSpec
+	cancel         context.CancelFunc
+}
+
+func (p *poolerMock) ReconcilePool(_ context.Context, spec runnerpkg.PoolSpec) (runnerpkg.PoolStatus, error) {
+	p.spec = spec
+	p.reconciles++
+
+	if p.cancel != nil {
+		p.cancel()
+	}
+
+	return runnerpkg.PoolStatus{Warm: spec.Size}, p.reconcileErr
+}
+
+func (p *poolerMock) ClaimRunner(_ context.Context, _, _, _, _, _ string,
+	template runnerpkg.TemplateOptions, overrides runnerpkg.ResourceOverrides, _ runnerpkg.ResourceMetadata,
+) error {
+	p.claimCalled = true
+	p.claimTemplate = template
+	p.claimOverrides = overrides
+
+	return p.claimErr
+}
+
+var _ = Describe("Pool", func() {
+	var pooler *poolerMock
+
+	BeforeEach(func() {
+		pooler = &poolerMock{}
+	})
+
+	Context("command", func() {
+		It("reconciles the pool until it is stopped", func() {
+			ctx, cancel := context.WithCancel(context.TODO())
+			pooler.cancel = cancel
+
+			cmd := app.NewRootCommand(ctx, pooler, app.Opts{})
+			cmd.SetArgs([]string{
+				"pool", "--pool", "windows", "--pool-size", "3", "-t", "windows-2022", "--memory", "8Gi",
+				"--disk-restore-strategy", "clone",
+			})
+
+			Expect(cmd.Execute()).To(Succeed())
+			Expect(pooler.reconciles).To(Equal(1))
+			Expect(pooler.spec.Name).To(Equal("windows"))
+			Expect(pooler.spec.Size).To(Equal(3))
+			Expect(pooler.spec.Template).To(Equal("windows-2022"))
+			Expect(pooler.spec.Overrides.Memory.String()).To(Equal("8Gi"))
+			Expect(pooler.spec.TemplateOptions.DiskRestoreStrategy).To(Equal(runnerpkg.DiskRestoreClone))
+		})
+
+		It("keeps reconciling after a failure", func() {
+			ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+			defer cancel()
+
+			pooler.reconcileErr = errExpectedFailure
+
+			cmd := app.NewRootCommand(ctx, pooler, app.Opts{})
+			cmd.SetArgs([]string{"pool", "--pool", "windows", "--pool-interval", "10ms"})
+
+			Expect(cmd.Execute()).To(Succeed())
+			Expect(pooler.reconciles).To(BeNumerically(">", 1))
+		})
+
+		It("stops when the pool is invalid", func() {
+			pooler.reconcileErr = runnerpkg.ErrInvalidPoolSpec
+
+			cmd := app.NewRootCommand(context.TODO(), pooler, app.Opts{})
+			cmd.SetArgs([]string{"pool", "--pool", "Invalid_Name"})
+			cmd.SilenceUsage = true
+
+			err := cmd.Execute()
+
+			Expect(err).To(MatchError(runnerpkg.ErrInvalidPoolSpec))
+			Expect(app.ExitCode(err)).To(Equal(app.ExitInvalidInput))
+		})
+	})
+
+	Context("claim", func() {
+		run := func(args ...string) error {
+			cmd := app.NewRootCommand(context.TODO(), pooler, app.Opts{})
+			cmd.SetArgs(append([]string{"-c", "jitconfig"}, args...))
+			cmd.SilenceUsage = true
+
+			return cmd.Execute()
+		}
+
+		It("adopts a warm runner of the pool", func() {
+			Expect(run("--pool", "windows")).To(Succeed())
+			Expect(pooler.claimCalled).To(BeTrue())
+			Expect(pooler.createCalled).To(BeFalse())
+			Expect(pooler.waitCalled).To(BeTrue())
+		})
+
+		It("creates a new runner when the pool has none", func() {
+			pooler.claimErr = runnerpkg.ErrNoWarmRunner
+
+			Expect(run("--pool", "windows")).To(Succeed())
+			Expect(pooler.claimCalled).To(BeTrue())
+			Expect(pooler.createCalled).To(BeTrue())
+		})
+
+		It("claims a warm runner with the resource overrides and the disk restore strategy", func() {
+			Expect(run("--pool", "windows", "--cpu-cores", "4", "--disk-restore-strategy", "clone")).To(Succeed())
+			Expect(pooler.claimCalled).To(BeTrue())
+			Expect(pooler.claimOverrides.CPUCores).To(Equal(uint32(4)))
+			Expect(pooler.claimTemplate.DiskRestoreStrategy).To(Equal(runnerpkg.DiskRestoreClone))
+			Expect(pooler.createCalled).To(BeFalse())
+		})
+
+		It("adopts a warm runner whatever the template source", func() {
+			Expect(run("--pool", "windows", "--template-source", "clustertemplate")).To(Succeed())
+			Expect(pooler.claimCalled).To(BeTrue())
+			Expect(pooler.createCalled).To(BeFalse())
+		})
+
+		It("fails when the claim fails", func() {
+			pooler.claimErr = errExpectedFailure
+
+			err := run("--pool", "windows")
+
+			Expect(err).To(MatchError(errExpectedFailure))
+			Expect(app.ExitCode(err)).To(Equal(app.ExitCreateFailure))
+			Expect(pooler.createCalled).To(BeFalse())
+		})
+
+		It("ignores the pools unless one is configured", func() {
+			Expect(run()).To(Succeed())
+			Expect(pooler.claimCalled).To(BeFalse())
+			Expect(pooler.createCalled).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,57 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
+)
+
+// preserveOnFailure keeps the runner resources for ttl when the runner failed
+// or timed out, so the virtual machine can be inspected. Interrupted runs are
+// always cleaned up.
+func preserveOnFailure(ctx context.Context, candidate runner.Runner, ttl time.Duration, waitErr error) {
+	if ttl <= 0 || ctx.Err() != nil || !isPreservableFailure(waitErr) {
+		return
+	}
+
+	preserver, ok := candidate.(runner.Preserver)
+	if !ok {
+		return
+	}
+
+	err := preserver.PreserveResources(ctx, ttl)
+	if err != nil {
+		utils.GetLogger().Warnf("failed to preserve the runner resources: %v", err)
+	}
+}
+
+func isPreservableFailure(err error) bool {
+	return errors.Is(err, runner.ErrRunnerFailed) ||
+		errors.Is(err, runner.ErrWaitTimeout) ||
+		errors.Is(err, runner.ErrScheduleTimeout) ||
+		errors.Is(err, runner.ErrBootTimeout) ||
+		errors.Is(err, runner.ErrReadyTimeout) ||
+		errors.Is(err, runner.ErrUnschedulable) ||
+		errors.Is(err, runner.ErrDataVolumeFailed)
+}
@@ -0,0 +1,62 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package app_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/cmd/kar/app"
+	runnerpkg "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type preserverMock struct {
+	mock
+
+	ttl time.Duration
+}
+
+func (p *preserverMock) PreserveResources(_ context.Context, ttl time.Duration) error {
+	p.ttl = ttl
+
+	return nil
+}
+
+var _ = Describe("Preserve on failure", func() {
+	DescribeTable("preserves the runner resources", func(waitErr error, args []string, expected time.Duration) {
+		runner := &preserverMock{mock: mock{waitErr: waitErr}}
+		cmd := app.NewRootCommand(context.TODO(), runner, app.Opts{})
+		cmd.SetArgs(args)
+		cmd.SilenceUsage = true
+
+		Expect(cmd.Execute()).To(MatchError(waitErr))
+		Expect(runner.ttl).To(Equal(expected))
+	},
+		Entry("when the runner failed", runnerpkg.ErrRunnerFailed,
+			[]string{"--preserve-on-failure", "2h"}, 2*time.Hour),
+		Entry("when the runner timed out", runnerpkg.ErrScheduleTimeout,
+			[]string{"--preserve-on-failure", "30m"}, 30*time.Minute),
+		Entry("unless the option is disabled", runnerpkg.ErrRunnerFailed,
+			[]string{}, time.Duration(0)),
+		Entry("unless the wait failed for another reason", errExpectedFailure,
+			[]string{"--preserve-on-failure", "2h"}, time.Duration(0)),
+	)
+})
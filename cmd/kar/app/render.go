@@ -0,0 +1,153 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	renderOutputYAML = "yaml"
+	renderOutputJSON = "json"
+)
+
+// ErrInvalidRenderOutput indicates that the render output format isn't supported.
+var ErrInvalidRenderOutput = errors.New("invalid render output format")
+
+// RenderOpts stores all the options for configuring the render command, on
+// top of the ones of the root command.
+type RenderOpts struct {
+	Opts
+
+	ServerDryRun bool
+	Output       string
+}
+
+// NewRenderCommand returns the command that prints the resources kar would
+// create for a runner, without creating them.
+func NewRenderCommand(ctx context.Context, renderer runner.Renderer) *cobra.Command {
+	var opts RenderOpts
+
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Print the runner resources generated from the template without creating them",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runRender(ctx, cmd.OutOrStdout(), renderer, opts)
+		},
+	}
+
+	installFlags(cmd.Flags(), &opts.Opts)
+	installRenderFlags(cmd.Flags(), &opts)
+
+	return cmd
+}
+
+// addRenderCommand registers the render subcommand when the runner is able
+// to render its resources.
+func addRenderCommand(ctx context.Context, root *cobra.Command, candidate runner.Runner) {
+	if renderer, ok := candidate.(runner.Renderer); ok {
+		root.AddCommand(NewRenderCommand(ctx, renderer))
+	}
+}
+
+func installRenderFlags(flags *pflag.FlagSet, cmdOptions *RenderOpts) {
+	flags.BoolVar(&cmdOptions.ServerDryRun, "server-dry-run", false,
+		"Submit the resources to the API server in dry-run mode, so admission webhooks validate them.")
+	flags.StringVarP(&cmdOptions.Output, "output", "o", renderOutputYAML,
+		"The output format, either yaml or json.")
+}
+
+func runRender(ctx context.Context, out io.Writer, renderer runner.Renderer, opts RenderOpts) error {
+	if opts.Output != renderOutputYAML && opts.Output != renderOutputJSON {
+		return fmt.Errorf("%w: %q", ErrInvalidRenderOutput, opts.Output)
+	}
+
+	resolved, err := resolveTemplate(opts.Opts)
+	if err != nil {
+		return err
+	}
+
+	template, err := templateOptions(resolved)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCreateResources, err)
+	}
+
+	overrides, err := resourceOverrides(resolved)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCreateResources, err)
+	}
+
+	rendered, err := renderer.RenderResources(ctx, runner.RenderOptions{
+		VMTemplate:          resolved.VMTemplate,
+		VMTemplateNamespace: resolved.VMTemplateNamespace,
+		RunnerName:          resolved.RunnerName,
+		Template:            template,
+		Overrides:           overrides,
+		Metadata:            resourceMetadata(resolved),
+		ServerDryRun:        opts.ServerDryRun,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCreateResources, err)
+	}
+
+	if opts.Output == renderOutputJSON {
+		return writeRenderJSON(out, rendered.Objects())
+	}
+
+	return writeRenderYAML(out, rendered.Objects())
+}
+
+// writeRenderJSON prints the objects as a Kubernetes List.
+func writeRenderJSON(out io.Writer, objects []any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(map[string]any{"apiVersion": "v1", "kind": "List", "items": objects})
+	if err != nil {
+		return fmt.Errorf("cannot encode the rendered resources: %w", err)
+	}
+
+	return nil
+}
+
+// writeRenderYAML prints the objects as a multi-document YAML stream.
+func writeRenderYAML(out io.Writer, objects []any) error {
+	for _, object := range objects {
+		data, err := yaml.Marshal(object)
+		if err != nil {
+			return fmt.Errorf("cannot encode the rendered resources: %w", err)
+		}
+
+		_, err = fmt.Fprintf(out, "---\n%s", data)
+		if err != nil {
+			return fmt.Errorf("cannot write the rendered resources: %w", err)
+		}
+	}
+
+	return nil
+}
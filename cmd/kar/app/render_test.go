@@ -0,0 +1,123 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/cmd/kar/app"
+	runnerpkg "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "kubevirt.io/api/core/v1"
+)
+
+type rendererMock struct {
+	mock
+
+	renderErr error
+	options   runnerpkg.RenderOptions
+}
+
+func (r *rendererMock) RenderResources(
+	_ context.Context,
+	opts runnerpkg.RenderOptions,
+) (*runnerpkg.RenderedResources, error) {
+	r.options = opts
+
+	if r.renderErr != nil {
+		return nil, r.renderErr
+	}
+
+	vmi := v1.NewVMIReferenceFromNameWithNS(k8sv1.NamespaceDefault, opts.RunnerName)
+	secret := &k8sv1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: "runner-info-" + opts.RunnerName},
+		StringData: map[string]string{"runner-info.json": `{"jitconfig":"REDACTED"}`},
+	}
+
+	return &runnerpkg.RenderedResources{VirtualMachineInstance: vmi, Secret: secret}, nil
+}
+
+var _ = Describe("Render", func() {
+	var (
+		renderer *rendererMock
+		out      *bytes.Buffer
+	)
+
+	execute := func(args ...string) error {
+		cmd := app.NewRootCommand(context.TODO(), renderer, app.Opts{})
+		cmd.SetOut(out)
+		cmd.SetArgs(append([]string{"render"}, args...))
+
+		return cmd.Execute()
+	}
+
+	BeforeEach(func() {
+		renderer = &rendererMock{}
+		out = &bytes.Buffer{}
+	})
+
+	It("prints the rendered resources as YAML documents", func() {
+		Expect(execute("-t", "windows-2022", "-r", "runner-abc", "--memory", "8Gi")).To(Succeed())
+
+		Expect(renderer.createCalled).To(BeFalse())
+		Expect(renderer.options.VMTemplate).To(Equal("windows-2022"))
+		Expect(renderer.options.RunnerName).To(Equal("runner-abc"))
+		Expect(renderer.options.Overrides.Memory.String()).To(Equal("8Gi"))
+		Expect(renderer.options.ServerDryRun).To(BeFalse())
+		Expect(out.String()).To(HavePrefix("---\napiVersion: kubevirt.io/v1\nkind: VirtualMachineInstance\n"))
+		Expect(out.String()).To(ContainSubstring("---\napiVersion: v1\nkind: Secret\n"))
+		Expect(out.String()).To(ContainSubstring(`runner-info.json: '{"jitconfig":"REDACTED"}'`))
+	})
+
+	It("prints the rendered resources as a JSON list", func() {
+		Expect(execute("-o", "json", "--server-dry-run")).To(Succeed())
+
+		var list struct {
+			Kind  string           `json:"kind"`
+			Items []map[string]any `json:"items"`
+		}
+		Expect(json.Unmarshal(out.Bytes(), &list)).To(Succeed())
+		Expect(list.Kind).To(Equal("List"))
+		Expect(list.Items).To(HaveLen(2))
+		Expect(list.Items[0]).To(HaveKeyWithValue("kind", "VirtualMachineInstance"))
+		Expect(renderer.options.ServerDryRun).To(BeTrue())
+	})
+
+	It("rejects an unknown output format", func() {
+		err := execute("-o", "table")
+
+		Expect(err).To(MatchError(app.ErrInvalidRenderOutput))
+		Expect(app.ExitCode(err)).To(Equal(app.ExitInvalidInput))
+	})
+
+	It("reports the template errors like a runner creation", func() {
+		renderer.renderErr = runnerpkg.ErrTemplateNotFound
+
+		err := execute()
+
+		Expect(err).To(MatchError(app.ErrCreateResources))
+		Expect(app.ExitCode(err)).To(Equal(app.ExitTemplateNotFound))
+	})
+})
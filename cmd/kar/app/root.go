@@ -41,32 +41,98 @@ func NewRootCommand(ctx context.Context, runner runner.Runner, opts Opts) *cobra
 
 	installFlags(cmd.Flags(), &opts)
 
+	addGCCommand(ctx, cmd, runner)
+	addPoolCommand(ctx, cmd, runner)
+	addRenderCommand(ctx, cmd, runner)
+
 	return cmd
 }
 
 func run(ctx context.Context, runner runner.Runner, opts Opts) error {
 	log := utils.GetLogger()
 
-	err := runner.CreateResources(ctx, opts.VMTemplate, opts.VMTemplateNamespace, opts.RunnerName, opts.JitConfig)
+	opts, err := resolveTemplate(opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.TemplateMapping != "" {
+		log.Printf("Selected %s/%s Virtual Machine template\n", opts.VMTemplateNamespace, opts.VMTemplate)
+	}
+
+	template, err := templateOptions(opts)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCreateResources, err)
+	}
+
+	overrides, err := resourceOverrides(opts)
 	if err != nil {
-		return fmt.Errorf("failed to create resources: %w", err)
+		return fmt.Errorf("%w: %w", ErrCreateResources, err)
+	}
+
+	err = createRunner(ctx, runner, opts, template, overrides)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCreateResources, err)
 	}
 
 	log.Println("Virtual Machine runner resources created successfully")
 
 	err = runner.WaitForVirtualMachineInstance(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to wait for resources: %w", err)
+		preserveOnFailure(ctx, runner, opts.PreserveOnFailure, err)
+
+		return fmt.Errorf("%w: %w", ErrWaitResources, err)
 	}
 
 	log.Println("Virtual Machine runner completed successfully")
 
 	err = runner.DeleteResources(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to delete resources: %w", err)
+		return fmt.Errorf("%w: %w", ErrDeleteResources, err)
 	}
 
 	log.Println("Virtual Machine runner deleted successfully")
 
 	return nil
 }
+
+func resourceMetadata(opts Opts) runner.ResourceMetadata {
+	return runner.ResourceMetadata{
+		GitHubRepository: opts.GitHubRepository,
+		GitHubWorkflow:   opts.GitHubWorkflow,
+		GitHubRunID:      opts.GitHubRunID,
+		Labels:           opts.ExtraLabels,
+		Annotations:      opts.ExtraAnnotations,
+	}
+}
+
+func resourceOverrides(opts Opts) (runner.ResourceOverrides, error) {
+	return parseResourceOverrides(opts.CPUCores, opts.Memory, opts.DiskSize)
+}
+
+func parseResourceOverrides(cpuCores uint32, memory, diskSize string) (runner.ResourceOverrides, error) {
+	overrides, err := runner.NewResourceOverrides(cpuCores, memory, diskSize)
+	if err != nil {
+		return runner.ResourceOverrides{}, fmt.Errorf("cannot parse resource overrides: %w", err)
+	}
+
+	return overrides, nil
+}
+
+func templateOptions(opts Opts) (runner.TemplateOptions, error) {
+	return parseTemplateOptions(opts.TemplateSource, opts.DiskRestoreStrategy)
+}
+
+func parseTemplateOptions(templateSource, diskRestoreStrategy string) (runner.TemplateOptions, error) {
+	source, err := runner.ParseTemplateSource(templateSource)
+	if err != nil {
+		return runner.TemplateOptions{}, fmt.Errorf("cannot parse template options: %w", err)
+	}
+
+	strategy, err := runner.ParseDiskRestoreStrategy(diskRestoreStrategy)
+	if err != nil {
+		return runner.TemplateOptions{}, fmt.Errorf("cannot parse template options: %w", err)
+	}
+
+	return runner.TemplateOptions{Source: source, DiskRestoreStrategy: strategy}, nil
+}
@@ -24,6 +24,7 @@ import (
 	"slices"
 
 	"github.com/electrocucaracha/kubevirt-actions-runner/cmd/kar/app"
+	runnerpkg "github.com/electrocucaracha/kubevirt-actions-runner/internal"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/spf13/cobra"
@@ -42,6 +43,9 @@ type mock struct {
 	vmTemplateNS string
 	runnerName   string
 	jitConfig    string
+	template     runnerpkg.TemplateOptions
+	overrides    runnerpkg.ResourceOverrides
+	metadata     runnerpkg.ResourceMetadata
 }
 
 type Failure uint8
@@ -63,11 +67,17 @@ func (m *mock) CreateResources(
 	vmTemplateNamespace,
 	runnerName,
 	jitConfig string,
+	template runnerpkg.TemplateOptions,
+	overrides runnerpkg.ResourceOverrides,
+	metadata runnerpkg.ResourceMetadata,
 ) error {
 	m.vmTemplate = vmTemplate
 	m.vmTemplateNS = vmTemplateNamespace
 	m.runnerName = runnerName
 	m.jitConfig = jitConfig
+	m.template = template
+	m.overrides = overrides
+	m.metadata = metadata
 
 	m.createCalled = true
 
@@ -169,4 +179,110 @@ var _ = Describe("Root Command", func() {
 		Entry("when the delete failed", false, Delete),
 		Entry("when the wait failed", false, Wait),
 	)
+
+	It("selects the vm template from the template mapping", func() {
+		mappingFile := writeTemplateMapping(`
+rules:
+  - match:
+      labels: [gpu]
+    template: gpu-template
+    namespace: gpu-vms
+`)
+		cmd.SetArgs([]string{"--kubevirt-vm-template-mapping", mappingFile, "--runner-labels", "linux,gpu"})
+
+		Expect(cmd.Execute()).To(Succeed())
+		Expect(runner.vmTemplate).To(Equal("gpu-template"))
+		Expect(runner.vmTemplateNS).To(Equal("gpu-vms"))
+	})
+
+	It("keeps the vm template flags when no mapping rule applies", func() {
+		mappingFile := writeTemplateMapping(`
+rules:
+  - match:
+      labels: [gpu]
+    template: gpu-template
+`)
+		cmd.SetArgs([]string{"--kubevirt-vm-template-mapping", mappingFile, "-t", "cpu-template"})
+
+		Expect(cmd.Execute()).To(Succeed())
+		Expect(runner.vmTemplate).To(Equal("cpu-template"))
+		Expect(runner.vmTemplateNS).To(Equal("default"))
+	})
+
+	It("passes the resource overrides to the runner", func() {
+		cmd.SetArgs([]string{"--cpu-cores", "4", "--memory", "8Gi", "--disk-size", "50Gi"})
+
+		Expect(cmd.Execute()).To(Succeed())
+		Expect(runner.overrides.CPUCores).To(Equal(uint32(4)))
+		Expect(runner.overrides.Memory.String()).To(Equal("8Gi"))
+		Expect(runner.overrides.DiskSize.String()).To(Equal("50Gi"))
+	})
+
+	It("passes the disk restore strategy to the runner", func() {
+		cmd.SetArgs([]string{"--disk-restore-strategy", "clone"})
+
+		Expect(cmd.Execute()).To(Succeed())
+		Expect(runner.template.DiskRestoreStrategy).To(Equal(runnerpkg.DiskRestoreClone))
+	})
+
+	It("passes the template source to the runner", func() {
+		cmd.SetArgs([]string{"--template-source", "configmap", "-t", "runner-templates/windows.yaml"})
+
+		Expect(cmd.Execute()).To(Succeed())
+		Expect(runner.vmTemplate).To(Equal("runner-templates/windows.yaml"))
+		Expect(runner.template.Source).To(Equal(runnerpkg.TemplateSourceConfigMap))
+	})
+
+	It("fails without creating resources when the template source is unknown", func() {
+		cmd.SetArgs([]string{"--template-source", "git"})
+
+		err := cmd.Execute()
+
+		Expect(err).To(MatchError(runnerpkg.ErrInvalidTemplateSource))
+		Expect(app.ExitCode(err)).To(Equal(app.ExitInvalidInput))
+		Expect(runner.createCalled).To(BeFalse())
+	})
+
+	It("fails without creating resources when the disk restore strategy is unknown", func() {
+		cmd.SetArgs([]string{"--disk-restore-strategy", "rsync"})
+
+		err := cmd.Execute()
+
+		Expect(err).To(MatchError(runnerpkg.ErrInvalidDiskRestoreStrategy))
+		Expect(app.ExitCode(err)).To(Equal(app.ExitInvalidInput))
+		Expect(runner.createCalled).To(BeFalse())
+	})
+
+	It("passes the resource metadata to the runner", func() {
+		cmd.SetArgs([]string{
+			"--github-repository", "octo/repo", "--github-workflow", "CI", "--github-run-id", "42",
+			"--extra-labels", "team=infra,tier=ci", "--extra-annotations", "example.com/owner=infra",
+		})
+
+		Expect(cmd.Execute()).To(Succeed())
+		Expect(runner.metadata).To(Equal(runnerpkg.ResourceMetadata{
+			GitHubRepository: "octo/repo",
+			GitHubWorkflow:   "CI",
+			GitHubRunID:      "42",
+			Labels:           map[string]string{"team": "infra", "tier": "ci"},
+			Annotations:      map[string]string{"example.com/owner": "infra"},
+		}))
+	})
+
+	It("fails without creating resources when a resource override is malformed", func() {
+		cmd.SetArgs([]string{"--memory", "lots"})
+
+		err := cmd.Execute()
+
+		Expect(err).To(MatchError(runnerpkg.ErrInvalidResourceOverride))
+		Expect(app.ExitCode(err)).To(Equal(app.ExitInvalidInput))
+		Expect(runner.createCalled).To(BeFalse())
+	})
+
+	It("fails without creating resources when the template mapping is invalid", func() {
+		cmd.SetArgs([]string{"--kubevirt-vm-template-mapping", writeTemplateMapping("rules: {}")})
+
+		Expect(cmd.Execute()).To(MatchError(app.ErrTemplateMapping))
+		Expect(runner.createCalled).To(BeFalse())
+	})
 })
@@ -25,6 +25,7 @@ import (
 	"os"
 	"os/signal"
 	"runtime/debug"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -32,6 +33,9 @@ import (
 	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
 	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
 	"github.com/spf13/pflag"
+	k8scorev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"kubevirt.io/client-go/kubecli"
 )
 
@@ -53,6 +57,10 @@ var (
 	// the "no build info available" branch of getBuildInfo deterministically.
 	readBuildInfo = debug.ReadBuildInfo
 
+	// osExit is a seam over os.Exit so tests can invoke main in-process and
+	// assert the exit code it reports.
+	osExit = os.Exit
+
 	// defaultCleanupTimeout, defaultWaitTimeout, and shutdownTimeout are computed by
 	// dedicated functions rather than declared as plain arithmetic constants. Go's
 	// coverage instrumentation does not track top-level const declarations, so
@@ -159,6 +167,48 @@ func setupTelemetry(ctx context.Context, log *utils.LoggerImpl) func(context.Con
 	return shutdownTelemetry
 }
 
+// runnerPod returns the pod running kar, discovered through the POD_NAME and
+// POD_NAMESPACE downward API variables, or nil when it's unknown.
+func runnerPod(ctx context.Context, virtClient kubecli.KubevirtClient, log *utils.LoggerImpl) *k8scorev1.Pod {
+	podName := os.Getenv("POD_NAME")
+	podNamespace := os.Getenv("POD_NAMESPACE")
+
+	if podName == "" || podNamespace == "" {
+		return nil
+	}
+
+	pod, err := virtClient.CoreV1().Pods(podNamespace).Get(ctx, podName, k8smetav1.GetOptions{})
+	if err != nil {
+		log.Warnf("failed to get runner pod %s/%s: %v", podNamespace, podName, err)
+
+		return nil
+	}
+
+	return pod
+}
+
+// podOptions returns the runner options that rely on the runner pod, along
+// with the function that flushes its events.
+func podOptions(
+	ctx context.Context,
+	virtClient kubecli.KubevirtClient,
+	log *utils.LoggerImpl,
+) ([]runner.Option, func()) {
+	pod := runnerPod(ctx, virtClient, log)
+	if pod == nil {
+		return nil, func() {}
+	}
+
+	events := runner.NewPodEventRecorder(virtClient.CoreV1(), pod)
+	opts := []runner.Option{runner.WithEventRecorder(events), runner.WithRunnerPod(pod)}
+
+	if os.Getenv("KAR_POD_OWNER_REFERENCE_ENABLED") != "false" {
+		opts = append(opts, runner.WithPodOwner())
+	}
+
+	return opts, events.Shutdown
+}
+
 // shutdownTelemetryAndLog invokes the telemetry shutdown function and logs a
 // warning if it fails, without terminating the process.
 func shutdownTelemetryAndLog(
@@ -173,15 +223,140 @@ func shutdownTelemetryAndLog(
 }
 
 // runCleanup deletes the runner's KubeVirt resources once the parent context
-// is done, logging any failure returned by DeleteResources.
-func runCleanup(ctx context.Context, kr runner.Runner, log *utils.LoggerImpl) {
+// is done, logging and returning any failure returned by DeleteResources.
+func runCleanup(ctx context.Context, kr runner.Runner, log *utils.LoggerImpl) error {
 	cleanupCtx, cancel := ensureValidCleanupContext(ctx)
 	defer cancel()
 
+	shutdownGuest(cleanupCtx, kr, log)
+
 	err := kr.DeleteResources(cleanupCtx)
 	if err != nil {
 		log.Println("cleanup failed:", err)
 	}
+
+	return err
+}
+
+// shutdownGuest gives the runner guest KAR_SHUTDOWN_GRACE_PERIOD to power off
+// before its resources are deleted. Failures are logged since the deletion
+// follows regardless.
+func shutdownGuest(ctx context.Context, kr runner.Runner, log *utils.LoggerImpl) {
+	gracePeriod := getDurationEnvOrDefault("KAR_SHUTDOWN_GRACE_PERIOD", 0)
+
+	shutdowner, ok := kr.(runner.GuestShutdowner)
+	if gracePeriod <= 0 || !ok {
+		return
+	}
+
+	err := shutdowner.ShutdownGuest(ctx, gracePeriod)
+	if err != nil {
+		log.Warnf("guest shutdown failed, deleting the runner resources: %v", err)
+	}
+}
+
+// runnerOptions translates the optional KAR_* environment variables into
+// runner options.
+func runnerOptions() []runner.Option {
+	var opts []runner.Option
+
+	if os.Getenv("KAR_SERIAL_CONSOLE_ENABLED") == "true" {
+		opts = append(opts, runner.WithSerialConsole())
+	}
+
+	if os.Getenv("KAR_VIRTUAL_MACHINE_ENABLED") == "true" {
+		opts = append(opts, runner.WithVirtualMachine())
+	}
+
+	phaseTimeouts := runner.PhaseTimeouts{
+		Scheduled: getDurationEnvOrDefault("KAR_SCHEDULE_TIMEOUT", 0),
+		Running:   getDurationEnvOrDefault("KAR_BOOT_TIMEOUT", 0),
+		Ready:     getDurationEnvOrDefault("KAR_READY_TIMEOUT", 0),
+
+		Unschedulable: getDurationEnvOrDefault("KAR_UNSCHEDULABLE_GRACE_PERIOD", 0),
+	}
+	if phaseTimeouts != (runner.PhaseTimeouts{}) {
+		utils.GetLogger().Printf("phase timeouts are set to: scheduled=%v running=%v ready=%v unschedulable=%v",
+			phaseTimeouts.Scheduled, phaseTimeouts.Running, phaseTimeouts.Ready, phaseTimeouts.Unschedulable)
+
+		opts = append(opts, runner.WithPhaseTimeouts(phaseTimeouts))
+	}
+
+	if os.Getenv("KAR_CLEANUP_WAIT_ENABLED") == "true" {
+		opts = append(opts, runner.WithWaitForDeletion(
+			getDurationEnvOrDefault("KAR_CLEANUP_TIMEOUT", defaultCleanupTimeout),
+			getDurationEnvOrDefault("KAR_CLEANUP_FORCE_AFTER", 0),
+		))
+	}
+
+	defaultRetryPolicy := runner.DefaultRetryPolicy()
+
+	retryPolicy := runner.RetryPolicy{
+		MaxAttempts: int(getUint32EnvOrDefault("KAR_RETRY_MAX_ATTEMPTS",
+			uint32(defaultRetryPolicy.MaxAttempts))),
+		InitialBackoff: getDurationEnvOrDefault("KAR_RETRY_INITIAL_BACKOFF", defaultRetryPolicy.InitialBackoff),
+		MaxBackoff:     getDurationEnvOrDefault("KAR_RETRY_MAX_BACKOFF", defaultRetryPolicy.MaxBackoff),
+	}
+	if retryPolicy != defaultRetryPolicy {
+		opts = append(opts, runner.WithRetryPolicy(retryPolicy))
+	}
+
+	if gracePeriod := getDurationEnvOrDefault("KAR_SHUTDOWN_GRACE_PERIOD", 0); gracePeriod > 0 {
+		opts = append(opts, runner.WithShutdownGracePeriod(gracePeriod))
+	}
+
+	if os.Getenv("KAR_DATA_VOLUMES_FIRST_ENABLED") == "true" {
+		opts = append(opts, runner.WithDataVolumesFirst())
+	}
+
+	if dir := os.Getenv("KAR_DIAGNOSTICS_DIR"); dir != "" {
+		opts = append(opts, runner.WithDiagnosticsDir(dir))
+	}
+
+	resourceLimits := runner.ResourceLimits{
+		MinCPUCores: getUint32EnvOrDefault("KAR_MIN_CPU_CORES", 0),
+		MaxCPUCores: getUint32EnvOrDefault("KAR_MAX_CPU_CORES", 0),
+		MinMemory:   getQuantityEnv("KAR_MIN_MEMORY"),
+		MaxMemory:   getQuantityEnv("KAR_MAX_MEMORY"),
+		MinDiskSize: getQuantityEnv("KAR_MIN_DISK_SIZE"),
+		MaxDiskSize: getQuantityEnv("KAR_MAX_DISK_SIZE"),
+	}
+	if resourceLimits != (runner.ResourceLimits{}) {
+		opts = append(opts, runner.WithResourceLimits(resourceLimits))
+	}
+
+	return opts
+}
+
+func getUint32EnvOrDefault(key string, defaultValue uint32) uint32 {
+	if val := os.Getenv(key); val != "" {
+		n, err := strconv.ParseUint(val, 10, 32)
+		if err == nil {
+			return uint32(n)
+		}
+
+		utils.GetLogger().Printf("Invalid %s value: %q, using default %d", key, val, defaultValue)
+	}
+
+	return defaultValue
+}
+
+// getQuantityEnv returns nil when the variable is unset or invalid, leaving
+// the corresponding limit unenforced.
+func getQuantityEnv(key string) *resource.Quantity {
+	val := os.Getenv(key)
+	if val == "" {
+		return nil
+	}
+
+	quantity, err := resource.ParseQuantity(val)
+	if err != nil {
+		utils.GetLogger().Printf("Invalid %s value: %q, ignoring it", key, val)
+
+		return nil
+	}
+
+	return &quantity
 }
 
 func getClientAndNamespace() (kubecli.KubevirtClient, string, error) {
@@ -200,16 +375,35 @@ func getClientAndNamespace() (kubecli.KubevirtClient, string, error) {
 	return virtClient, namespace, nil
 }
 
-func runMainApp(ctx context.Context, kr runner.Runner, log *utils.LoggerImpl) {
+// runMainApp executes the root command and returns the process exit code
+// that categorizes its outcome.
+func runMainApp(ctx context.Context, kr runner.Runner, log *utils.LoggerImpl) int {
 	rootCmd := app.NewRootCommand(ctx, kr, app.Opts{})
 
 	execErr := rootCmd.Execute()
-	if execErr != nil && !errors.Is(execErr, context.Canceled) {
-		log.Println("execute command failed:", execErr)
+	if execErr == nil {
+		return app.ExitSuccess
+	}
+
+	// A cancelled parent context means a signal interrupted the run, so any
+	// resulting error (including the wait timeout) is reported as such.
+	if errors.Is(execErr, context.Canceled) || ctx.Err() != nil {
+		return app.ExitInterrupted
 	}
+
+	log.Println("execute command failed:", execErr)
+
+	return app.ExitCode(execErr)
 }
 
 func main() {
+	osExit(runKar())
+}
+
+// runKar wires the runner dependencies, executes the root command and waits
+// for the cleanup to finish. It returns the process exit code so deferred
+// shutdown logic runs before main exits.
+func runKar() int {
 	log := utils.GetLogger()
 	buildInfo := getBuildInfo(gitCommit, buildDate, gitTreeModified)
 	log.Printf("starting kubevirt action runner\ncommit: %v\tmodified: %v\tdate: %v\tgo: %v\n",
@@ -228,11 +422,16 @@ func main() {
 	if err != nil {
 		log.Warnf("error getting client or namespace: %v\n", err)
 
-		return
+		return app.ExitFailure
 	}
 
+	podOpts, shutdownEvents := podOptions(context.Background(), virtClient, log)
+	defer shutdownEvents()
+
+	opts := append(runnerOptions(), podOpts...)
+
 	waitTimeout := getDurationEnvOrDefault("KAR_WAIT_TIMEOUT", defaultWaitTimeout)
-	kubevirtRunner := runner.NewRunner(namespace, virtClient, waitTimeout)
+	kubevirtRunner := runner.NewRunner(namespace, virtClient, waitTimeout, opts...)
 
 	log.Printf("cleanup timeout is set to: %v", getDurationEnvOrDefault("KAR_CLEANUP_TIMEOUT", defaultCleanupTimeout))
 	log.Printf("wait timeout is set to: %v", waitTimeout)
@@ -240,11 +439,23 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	cleanupErr := make(chan error, 1)
+
 	go func() {
 		<-ctx.Done()
 
-		runCleanup(ctx, kubevirtRunner, log)
+		cleanupErr <- runCleanup(ctx, kubevirtRunner, log)
 	}()
 
-	runMainApp(ctx, kubevirtRunner, log)
+	exitCode := runMainApp(ctx, kubevirtRunner, log)
+
+	stop()
+
+	if <-cleanupErr != nil && exitCode == app.ExitSuccess {
+		exitCode = app.ExitCleanupFailure
+	}
+
+	log.Printf("exiting with code %d", exitCode)
+
+	return exitCode
 }
@@ -26,7 +26,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/electrocucaracha/kubevirt-actions-runner/cmd/kar/app"
+	runnerpkg "github.com/electrocucaracha/kubevirt-actions-runner/internal"
 	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
+	"go.uber.org/mock/gomock"
+	k8scorev1 "k8s.io/api/core/v1"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"kubevirt.io/client-go/kubecli"
 )
 
 var errMainTestFailure = errors.New("simulated failure")
@@ -39,7 +46,9 @@ type mockRunner struct {
 	deleteErr error
 }
 
-func (m *mockRunner) CreateResources(_ context.Context, _, _, _, _ string) error {
+func (m *mockRunner) CreateResources(_ context.Context, _, _, _, _ string, _ runnerpkg.TemplateOptions,
+	_ runnerpkg.ResourceOverrides, _ runnerpkg.ResourceMetadata,
+) error {
 	return m.createErr
 }
 
@@ -248,6 +257,126 @@ func TestGetDurationEnvOrDefault(t *testing.T) {
 	}
 }
 
+func TestRunnerOptions(t *testing.T) {
+	t.Run("returns no options when nothing is enabled", func(t *testing.T) {
+		t.Setenv("KAR_SERIAL_CONSOLE_ENABLED", "")
+		t.Setenv("KAR_VIRTUAL_MACHINE_ENABLED", "")
+		t.Setenv("KAR_SCHEDULE_TIMEOUT", "")
+		t.Setenv("KAR_BOOT_TIMEOUT", "")
+		t.Setenv("KAR_READY_TIMEOUT", "")
+		t.Setenv("KAR_UNSCHEDULABLE_GRACE_PERIOD", "")
+		t.Setenv("KAR_MIN_CPU_CORES", "")
+		t.Setenv("KAR_MAX_CPU_CORES", "")
+		t.Setenv("KAR_MIN_MEMORY", "")
+		t.Setenv("KAR_MAX_MEMORY", "")
+		t.Setenv("KAR_MIN_DISK_SIZE", "")
+		t.Setenv("KAR_MAX_DISK_SIZE", "")
+		t.Setenv("KAR_CLEANUP_WAIT_ENABLED", "")
+		t.Setenv("KAR_RETRY_MAX_ATTEMPTS", "")
+		t.Setenv("KAR_RETRY_INITIAL_BACKOFF", "")
+		t.Setenv("KAR_RETRY_MAX_BACKOFF", "")
+		t.Setenv("KAR_DIAGNOSTICS_DIR", "")
+		t.Setenv("KAR_DATA_VOLUMES_FIRST_ENABLED", "")
+		t.Setenv("KAR_SHUTDOWN_GRACE_PERIOD", "")
+
+		if got := runnerOptions(); len(got) != 0 {
+			t.Fatalf("expected no runner options, got %d", len(got))
+		}
+	})
+
+	t.Run("enables the serial console streaming", func(t *testing.T) {
+		t.Setenv("KAR_SERIAL_CONSOLE_ENABLED", "true")
+
+		if got := runnerOptions(); len(got) != 1 {
+			t.Fatalf("expected one runner option, got %d", len(got))
+		}
+	})
+
+	t.Run("enables the virtual machine mode", func(t *testing.T) {
+		t.Setenv("KAR_SERIAL_CONSOLE_ENABLED", "")
+		t.Setenv("KAR_VIRTUAL_MACHINE_ENABLED", "true")
+
+		if got := runnerOptions(); len(got) != 1 {
+			t.Fatalf("expected one runner option, got %d", len(got))
+		}
+	})
+
+	t.Run("waits for the deletion of the runner resources", func(t *testing.T) {
+		t.Setenv("KAR_SERIAL_CONSOLE_ENABLED", "")
+		t.Setenv("KAR_CLEANUP_WAIT_ENABLED", "true")
+		t.Setenv("KAR_CLEANUP_FORCE_AFTER", "1m")
+
+		if got := runnerOptions(); len(got) != 1 {
+			t.Fatalf("expected one runner option, got %d", len(got))
+		}
+	})
+
+	t.Run("sets the termination grace period of the guest", func(t *testing.T) {
+		t.Setenv("KAR_SERIAL_CONSOLE_ENABLED", "")
+		t.Setenv("KAR_SHUTDOWN_GRACE_PERIOD", "45s")
+
+		if got := runnerOptions(); len(got) != 1 {
+			t.Fatalf("expected one runner option, got %d", len(got))
+		}
+	})
+
+	t.Run("configures the retry policy when any of its settings is set", func(t *testing.T) {
+		t.Setenv("KAR_SERIAL_CONSOLE_ENABLED", "")
+		t.Setenv("KAR_RETRY_MAX_ATTEMPTS", "1")
+
+		if got := runnerOptions(); len(got) != 1 {
+			t.Fatalf("expected one runner option, got %d", len(got))
+		}
+	})
+
+	t.Run("writes a diagnostics bundle when a directory is set", func(t *testing.T) {
+		t.Setenv("KAR_SERIAL_CONSOLE_ENABLED", "")
+		t.Setenv("KAR_DIAGNOSTICS_DIR", "/var/run/kar/diagnostics")
+
+		if got := runnerOptions(); len(got) != 1 {
+			t.Fatalf("expected one runner option, got %d", len(got))
+		}
+	})
+
+	t.Run("creates the data volumes before the vmi", func(t *testing.T) {
+		t.Setenv("KAR_SERIAL_CONSOLE_ENABLED", "")
+		t.Setenv("KAR_DATA_VOLUMES_FIRST_ENABLED", "true")
+
+		if got := runnerOptions(); len(got) != 1 {
+			t.Fatalf("expected one runner option, got %d", len(got))
+		}
+	})
+
+	t.Run("configures the resource limits when any of them is set", func(t *testing.T) {
+		t.Setenv("KAR_SERIAL_CONSOLE_ENABLED", "")
+		t.Setenv("KAR_MAX_CPU_CORES", "8")
+		t.Setenv("KAR_MAX_MEMORY", "16Gi")
+
+		if got := runnerOptions(); len(got) != 1 {
+			t.Fatalf("expected one runner option, got %d", len(got))
+		}
+	})
+
+	t.Run("ignores invalid resource limits", func(t *testing.T) {
+		t.Setenv("KAR_SERIAL_CONSOLE_ENABLED", "")
+		t.Setenv("KAR_MAX_CPU_CORES", "many")
+		t.Setenv("KAR_MAX_MEMORY", "lots")
+
+		if got := runnerOptions(); len(got) != 0 {
+			t.Fatalf("expected no runner options, got %d", len(got))
+		}
+	})
+
+	t.Run("configures the phase timeouts when any of them is set", func(t *testing.T) {
+		t.Setenv("KAR_SERIAL_CONSOLE_ENABLED", "")
+		t.Setenv("KAR_SCHEDULE_TIMEOUT", "10m")
+
+		if got := runnerOptions(); len(got) != 1 {
+			t.Fatalf("expected one runner option, got %d", len(got))
+		}
+	})
+}
+
 func TestEnsureValidCleanupContext(t *testing.T) {
 	t.Parallel()
 
@@ -347,6 +476,28 @@ func assertClientAndNamespaceError(t *testing.T, client any, namespace string, e
 	}
 }
 
+func assertExitCode(t *testing.T, got, want int) {
+	t.Helper()
+
+	if got != want {
+		t.Fatalf("expected exit code %d, got %d", want, got)
+	}
+}
+
+// captureExit swaps the osExit seam for the duration of the test and returns
+// a pointer to the last exit code reported by main.
+func captureExit(t *testing.T) *int {
+	t.Helper()
+
+	exitCode := -1
+	origOsExit := osExit
+	osExit = func(code int) { exitCode = code }
+
+	t.Cleanup(func() { osExit = origOsExit })
+
+	return &exitCode
+}
+
 func assertShutdownNoError(t *testing.T, shutdown func(context.Context) error) {
 	t.Helper()
 
@@ -430,6 +581,61 @@ func TestSetupTelemetry(t *testing.T) {
 	})
 }
 
+func TestPodOptions(t *testing.T) {
+	log := utils.GetLogger()
+	pod := &k8scorev1.Pod{ObjectMeta: k8smetav1.ObjectMeta{Name: "runner-pod", Namespace: "runners"}}
+
+	newVirtClient := func(t *testing.T) kubecli.KubevirtClient {
+		t.Helper()
+
+		virtClient := kubecli.NewMockKubevirtClient(gomock.NewController(t))
+		virtClient.EXPECT().CoreV1().Return(k8sfake.NewSimpleClientset(pod).CoreV1()).AnyTimes()
+
+		return virtClient
+	}
+
+	assertPodOptions := func(t *testing.T, expected int) {
+		t.Helper()
+
+		opts, shutdown := podOptions(context.Background(), newVirtClient(t), log)
+		defer shutdown()
+
+		if len(opts) != expected {
+			t.Fatalf("expected %d runner options, got %d", expected, len(opts))
+		}
+	}
+
+	t.Run("returns no options when the pod isn't known", func(t *testing.T) {
+		t.Setenv("POD_NAME", "")
+		t.Setenv("POD_NAMESPACE", "runners")
+
+		assertPodOptions(t, 0)
+	})
+
+	t.Run("returns no options when the pod cannot be found", func(t *testing.T) {
+		t.Setenv("POD_NAME", "missing-pod")
+		t.Setenv("POD_NAMESPACE", "runners")
+
+		assertPodOptions(t, 0)
+	})
+
+	t.Run("records events on the pod and makes it the owner of the runner", func(t *testing.T) {
+		t.Setenv("POD_NAME", "runner-pod")
+		t.Setenv("POD_NAMESPACE", "runners")
+		t.Setenv("KAR_POD_OWNER_REFERENCE_ENABLED", "")
+
+		assertPodOptions(t, 3)
+	})
+
+	t.Run("skips the owner reference when it is disabled", func(t *testing.T) {
+		t.Setenv("POD_NAME", "runner-pod")
+		t.Setenv("POD_NAMESPACE", "runners")
+		t.Setenv("KAR_POD_OWNER_REFERENCE_ENABLED", "false")
+
+		assertPodOptions(t, 2)
+	})
+}
+
 func TestShutdownTelemetryAndLog(t *testing.T) {
 	t.Parallel()
 
@@ -471,14 +677,65 @@ func TestRunCleanup(t *testing.T) {
 		t.Parallel()
 
 		runner := &mockRunner{}
-		runCleanup(context.Background(), runner, log)
+
+		err := runCleanup(context.Background(), runner, log)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
 	})
 
 	t.Run("logs cleanup failure when DeleteResources returns an error", func(t *testing.T) {
 		t.Parallel()
 
 		runner := &mockRunner{deleteErr: errMainTestFailure}
-		runCleanup(context.Background(), runner, log)
+
+		err := runCleanup(context.Background(), runner, log)
+		if !errors.Is(err, errMainTestFailure) {
+			t.Fatalf("expected error %v, got %v", errMainTestFailure, err)
+		}
+	})
+}
+
+type shutdownRunner struct {
+	mockRunner
+
+	gracePeriod time.Duration
+	shutdownErr error
+}
+
+func (m *shutdownRunner) ShutdownGuest(_ context.Context, gracePeriod time.Duration) error {
+	m.gracePeriod = gracePeriod
+
+	return m.shutdownErr
+}
+
+func TestShutdownGuest(t *testing.T) {
+	log := utils.GetLogger()
+
+	t.Run("skips the shutdown when no grace period is set", func(t *testing.T) {
+		t.Setenv("KAR_SHUTDOWN_GRACE_PERIOD", "")
+
+		runner := &shutdownRunner{}
+		shutdownGuest(context.Background(), runner, log)
+
+		if runner.gracePeriod != 0 {
+			t.Fatalf("expected no shutdown, got grace period %v", runner.gracePeriod)
+		}
+	})
+
+	t.Run("shuts the guest down before the cleanup", func(t *testing.T) {
+		t.Setenv("KAR_SHUTDOWN_GRACE_PERIOD", "45s")
+
+		runner := &shutdownRunner{shutdownErr: errMainTestFailure}
+
+		err := runCleanup(context.Background(), runner, log)
+		if err != nil {
+			t.Fatalf("expected the cleanup to ignore the shutdown failure, got %v", err)
+		}
+
+		if runner.gracePeriod != 45*time.Second {
+			t.Fatalf("expected a 45s grace period, got %v", runner.gracePeriod)
+		}
 	})
 }
 
@@ -493,14 +750,21 @@ func TestRunMainApp(t *testing.T) {
 		runner := &mockRunner{}
 		// runMainApp should not panic and should invoke the root command
 		// against the provided runner without requiring a real KubeVirt client.
-		runMainApp(context.Background(), runner, log)
+		assertExitCode(t, runMainApp(context.Background(), runner, log), app.ExitSuccess)
 	})
 
 	t.Run("logs failure when execution returns a non-cancellation error", func(t *testing.T) {
 		t.Parallel()
 
 		runner := &mockRunner{createErr: errMainTestFailure}
-		runMainApp(context.Background(), runner, log)
+		assertExitCode(t, runMainApp(context.Background(), runner, log), app.ExitCreateFailure)
+	})
+
+	t.Run("reports the runner failure exit code when the VMI fails", func(t *testing.T) {
+		t.Parallel()
+
+		runner := &mockRunner{waitErr: runnerpkg.ErrRunnerFailed}
+		assertExitCode(t, runMainApp(context.Background(), runner, log), app.ExitRunnerFailed)
 	})
 
 	t.Run("suppresses logging when execution is cancelled", func(t *testing.T) {
@@ -510,7 +774,17 @@ func TestRunMainApp(t *testing.T) {
 		cancel()
 
 		runner := &mockRunner{}
-		runMainApp(ctx, runner, log)
+		assertExitCode(t, runMainApp(ctx, runner, log), app.ExitSuccess)
+	})
+
+	t.Run("reports an interruption when execution fails after cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		runner := &mockRunner{waitErr: runnerpkg.ErrWaitTimeout}
+		assertExitCode(t, runMainApp(ctx, runner, log), app.ExitInterrupted)
 	})
 }
 
@@ -530,6 +804,7 @@ func TestMain_DirectInvocation(t *testing.T) {
 
 	t.Run("returns early when client/namespace resolution fails", func(t *testing.T) {
 		os.Args = []string{"kar"}
+		exitCode := captureExit(t)
 
 		t.Setenv("KUBECONFIG", writeTempKubeconfig(t, malformedKubeconfig))
 		t.Setenv("KAR_TELEMETRY_ENABLED", "false")
@@ -537,10 +812,13 @@ func TestMain_DirectInvocation(t *testing.T) {
 		// main() should log the resolution error and return early, without
 		// panicking or reaching the runner/command-execution setup below it.
 		main()
+
+		assertExitCode(t, *exitCode, app.ExitFailure)
 	})
 
 	t.Run("proceeds through command execution and signal-triggered cleanup", func(t *testing.T) {
 		os.Args = []string{"kar"}
+		exitCode := captureExit(t)
 
 		t.Setenv("KUBECONFIG", t.TempDir()+"/nonexistent-kubeconfig")
 		t.Setenv("KAR_TELEMETRY_ENABLED", "false")
@@ -548,15 +826,11 @@ func TestMain_DirectInvocation(t *testing.T) {
 
 		// With no jitconfig flag supplied, the root command's RunE fails fast
 		// (without making any real KubeVirt API call), so main() reaches the
-		// end of its body quickly. Its deferred stop() then cancels the
-		// signal-notification context, unblocking the cleanup goroutine,
-		// which attempts (and fails fast against) a real DeleteResources
-		// call, exercising that goroutine's error-logging path as well.
+		// end of its body quickly. It then cancels the signal-notification
+		// context, unblocking the cleanup goroutine, and waits for its
+		// DeleteResources call before reporting the exit code.
 		main()
 
-		// Give the cleanup goroutine a brief moment to finish running before
-		// this subtest returns, so its coverage counters are recorded
-		// deterministically rather than racing the test binary's exit.
-		time.Sleep(200 * time.Millisecond)
+		assertExitCode(t, *exitCode, app.ExitInvalidInput)
 	})
 }
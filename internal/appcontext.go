@@ -19,14 +19,16 @@ limitations under the License.
 package runner
 
 import (
+	"slices"
 	"sync"
 
 	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
 )
 
 type AppContext struct {
-	vmiName        string
-	dataVolumeName string
+	vmiName         string
+	dataVolumeNames []string
+	secretName      string
 }
 
 //nolint:gochecknoglobals
@@ -37,16 +39,18 @@ var (
 
 // NewAppContext creates the AppContext once with the provided values.
 // Subsequent calls return the same instance, ignoring new values.
-func NewAppContext(vmi, dataVolume string) *AppContext {
+func NewAppContext(vmi string, dataVolumes []string, secret string) *AppContext {
 	appContextMu.Lock()
 	defer appContextMu.Unlock()
 
 	if instance == nil {
-		utils.GetLogger().Printf("Registering %s Virtual Machine Instance and %s Data Volume\n", vmi, dataVolume)
+		utils.GetLogger().Printf("Registering %s Virtual Machine Instance, %v Data Volumes and %s Secret\n",
+			vmi, dataVolumes, secret)
 
 		instance = &AppContext{
-			vmiName:        vmi,
-			dataVolumeName: dataVolume,
+			vmiName:         vmi,
+			dataVolumeNames: slices.Clone(dataVolumes),
+			secretName:      secret,
 		}
 	}
 
@@ -87,7 +91,12 @@ func (a *AppContext) GetVMIName() string {
 	return a.vmiName
 }
 
-// GetDataVolumeName returns the Data Volume Name created for the runner.
-func (a *AppContext) GetDataVolumeName() string {
-	return a.dataVolumeName
+// GetDataVolumeNames returns the Data Volume Names created for the runner.
+func (a *AppContext) GetDataVolumeNames() []string {
+	return slices.Clone(a.dataVolumeNames)
+}
+
+// GetSecretName returns the runner-info Secret Name created for the runner.
+func (a *AppContext) GetSecretName() string {
+	return a.secretName
 }
@@ -23,6 +23,7 @@ import (
 	"errors"
 	"os"
 	"os/exec"
+	"slices"
 	"testing"
 
 	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
@@ -44,20 +45,24 @@ func expectExitCode(t *testing.T, err error, expected int) {
 func TestCancelAppContextResetsSingleton(t *testing.T) {
 	t.Cleanup(runner.CancelAppContext)
 
-	ctx := runner.NewAppContext("first-vmi", "first-dv")
+	ctx := runner.NewAppContext("first-vmi", []string{"first-dv"}, "first-secret")
 	if got := ctx.GetVMIName(); got != "first-vmi" {
 		t.Fatalf("expected first VMI name, got %q", got)
 	}
 
 	runner.CancelAppContext()
 
-	ctx = runner.NewAppContext("second-vmi", "second-dv")
+	ctx = runner.NewAppContext("second-vmi", []string{"second-dv", "second-scratch-dv"}, "second-secret")
 	if got := ctx.GetVMIName(); got != "second-vmi" {
 		t.Fatalf("expected reset VMI name, got %q", got)
 	}
 
-	if got := ctx.GetDataVolumeName(); got != "second-dv" {
-		t.Fatalf("expected reset data volume name, got %q", got)
+	if got := ctx.GetDataVolumeNames(); !slices.Equal(got, []string{"second-dv", "second-scratch-dv"}) {
+		t.Fatalf("expected reset data volume names, got %q", got)
+	}
+
+	if got := ctx.GetSecretName(); got != "second-secret" {
+		t.Fatalf("expected reset secret name, got %q", got)
 	}
 }
 
@@ -0,0 +1,138 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"kubevirt.io/client-go/kubecli"
+	kvcorev1 "kubevirt.io/client-go/kubevirt/typed/core/v1"
+)
+
+const (
+	// serialConsoleConnectTimeout bounds how long a single connection attempt
+	// keeps retrying while the VMI is not yet running.
+	serialConsoleConnectTimeout   = time.Minute
+	serialConsoleReconnectBackoff = watchReconnectBackoff
+)
+
+// streamSerialConsole copies the VMI serial console into the logger until the
+// context is done, reconnecting whenever the stream drops.
+func streamSerialConsole(
+	ctx context.Context,
+	span trace.Span,
+	vmiInterface kubecli.VirtualMachineInstanceInterface,
+	vmiName string,
+) {
+	log := utils.GetLogger()
+
+	for {
+		err := copySerialConsole(ctx, vmiInterface, vmiName)
+		if ctx.Err() != nil {
+			return
+		}
+
+		reason := "serial console stream closed"
+		if err != nil {
+			reason = err.Error()
+		}
+
+		log.Printf("Serial console stream closed for %s Virtual Machine Instance; reconnecting: %s\n", vmiName, reason)
+		span.AddEvent("serial_console_reconnect", trace.WithAttributes(attribute.String("reason", reason)))
+
+		timer := time.NewTimer(serialConsoleReconnectBackoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// copySerialConsole attaches to the VMI serial console once and logs every
+// line received until the stream ends or the context is done.
+func copySerialConsole(
+	ctx context.Context,
+	vmiInterface kubecli.VirtualMachineInstanceInterface,
+	vmiName string,
+) error {
+	stream, err := vmiInterface.SerialConsole(vmiName, &kvcorev1.SerialConsoleOptions{
+		ConnectionTimeout: serialConsoleConnectTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to the serial console: %w", err)
+	}
+
+	// The console input is never written; closing it is what ends the stream
+	// once the context is done.
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+
+	stopInput := context.AfterFunc(ctx, func() { _ = inWriter.Close() })
+	defer stopInput()
+
+	streamErr := make(chan error, 1)
+
+	go func() {
+		err := stream.Stream(kvcorev1.StreamOptions{In: inReader, Out: outWriter})
+		_ = outWriter.CloseWithError(err)
+		streamErr <- err
+	}()
+
+	logConsoleLines(vmiName, outReader)
+	_ = inWriter.Close()
+
+	err = <-streamErr
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("serial console stream failed: %w", err)
+	}
+
+	return nil
+}
+
+// logConsoleLines logs each console line prefixed with the VMI name so it can
+// be told apart from kar's own messages.
+func logConsoleLines(vmiName string, reader io.Reader) {
+	log := utils.GetLogger()
+	bufReader := bufio.NewReader(reader)
+
+	for {
+		line, err := bufReader.ReadString('\n')
+
+		line = strings.TrimRight(line, "\r\n")
+		if line != "" {
+			log.Printf("[%s console] %s", vmiName, line)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
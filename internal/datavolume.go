@@ -0,0 +1,350 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	k8scorev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	v1 "kubevirt.io/api/core/v1"
+	"kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+const (
+	// dataVolumePollInterval is how often the DataVolumes are checked for
+	// progress.
+	dataVolumePollInterval = 2 * time.Second
+	// dataVolumeMaxRestarts is how many times CDI may restart a failing
+	// import, clone or upload before the DataVolume is considered failed.
+	dataVolumeMaxRestarts = 3
+)
+
+// WithDataVolumesFirst creates the DataVolumes of the runner and waits for
+// them to be populated before the VMI is created, so import errors fail the
+// run before a VMI is scheduled.
+func WithDataVolumesFirst() Option {
+	return func(rc *KubevirtRunner) {
+		rc.dataVolumesFirst = true
+	}
+}
+
+// dataVolumeProgress reports the phase and progress changes of the runner
+// DataVolumes, remembering what was last reported for each of them.
+type dataVolumeProgress struct {
+	span     trace.Span
+	reported map[string]string
+}
+
+func newDataVolumeProgress(span trace.Span) *dataVolumeProgress {
+	return &dataVolumeProgress{span: span, reported: map[string]string{}}
+}
+
+func (p *dataVolumeProgress) report(dataVolume *v1beta1.DataVolume) {
+	phase, progress := dataVolume.Status.Phase, dataVolume.Status.Progress
+
+	current := fmt.Sprintf("%s %s", phase, progress)
+	if p.reported[dataVolume.Name] == current {
+		return
+	}
+
+	p.reported[dataVolume.Name] = current
+
+	utils.GetLogger().Printf("%s Data Volume is %s (%s)\n", dataVolume.Name, phase, progress)
+	p.span.AddEvent("data_volume_progress", trace.WithAttributes(
+		attribute.String("dataVolumeName", dataVolume.Name),
+		attribute.String("phase", string(phase)),
+		attribute.String("progress", string(progress)),
+	))
+}
+
+// pollDataVolumes checks every DataVolume once and returns how many of them
+// aren't populated yet. A DataVolume waiting for its first consumer only
+// counts as populated when waitingForConsumer is set, since it can't make any
+// progress before the VMI exists.
+func (rc *KubevirtRunner) pollDataVolumes(
+	ctx context.Context,
+	names []string,
+	progress *dataVolumeProgress,
+	waitingForConsumer bool,
+) (int, error) {
+	pending := 0
+
+	for _, name := range names {
+		dataVolume, err := rc.virtClient.CdiClient().CdiV1beta1().DataVolumes(rc.namespace).Get(
+			ctx, name, k8smetav1.GetOptions{})
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				utils.GetLogger().Warnf("failed to get data volume %s: %v", name, err)
+			}
+
+			pending++
+
+			continue
+		}
+
+		progress.report(dataVolume)
+
+		if reason := dataVolumeFailure(dataVolume); reason != "" {
+			rc.events.Warningf(EventReasonFailed, "Data volume %s %s", name, reason)
+
+			return 0, fmt.Errorf("%w: data volume %s %s", ErrDataVolumeFailed, name, reason)
+		}
+
+		if !isDataVolumePopulated(dataVolume.Status.Phase, waitingForConsumer) {
+			pending++
+		}
+	}
+
+	return pending, nil
+}
+
+func isDataVolumePopulated(phase v1beta1.DataVolumePhase, waitingForConsumer bool) bool {
+	switch phase {
+	case v1beta1.Succeeded:
+		return true
+	case v1beta1.WaitForFirstConsumer, v1beta1.PendingPopulation:
+		return waitingForConsumer
+	default:
+		return false
+	}
+}
+
+// dataVolumeFailure returns why the DataVolume failed, or an empty string
+// while it may still be populated. CDI retries failing imports, so an
+// import error is only final once it was restarted dataVolumeMaxRestarts
+// times.
+func dataVolumeFailure(dataVolume *v1beta1.DataVolume) string {
+	var message string
+
+	for _, condition := range dataVolume.Status.Conditions {
+		if condition.Type == v1beta1.DataVolumeRunning && condition.Reason == dataVolumeErrorReason {
+			message = condition.Message
+		}
+	}
+
+	switch {
+	case dataVolume.Status.Phase == v1beta1.Failed && message != "":
+		return "has failed: " + message
+	case dataVolume.Status.Phase == v1beta1.Failed:
+		return "has failed"
+	case message != "" && dataVolume.Status.RestartCount >= dataVolumeMaxRestarts:
+		return fmt.Sprintf("failed %d times: %s", dataVolume.Status.RestartCount, message)
+	default:
+		return ""
+	}
+}
+
+// monitorDataVolumes reports the progress of the runner DataVolumes until
+// they are all populated, and cancels the wait with the failure of any of
+// them.
+func (rc *KubevirtRunner) monitorDataVolumes(
+	ctx context.Context,
+	span trace.Span,
+	names []string,
+	cancel context.CancelCauseFunc,
+) {
+	progress := newDataVolumeProgress(span)
+
+	ticker := time.NewTicker(dataVolumePollInterval)
+	defer ticker.Stop()
+
+	for {
+		pending, err := rc.pollDataVolumes(ctx, names, progress, false)
+
+		switch {
+		case err != nil:
+			span.RecordError(err)
+			cancel(err)
+
+			return
+		case pending == 0:
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitForDataVolumes waits until deadline for the DataVolumes to be
+// populated, or wait for the VMI to consume them.
+func (rc *KubevirtRunner) waitForDataVolumes(
+	ctx context.Context,
+	tracer trace.Tracer,
+	names []string,
+	deadline time.Time,
+) error {
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	ctx, span := tracer.Start(ctx, "WaitForDataVolumes",
+		trace.WithAttributes(
+			attribute.StringSlice("dataVolumeNames", names),
+		),
+	)
+	defer span.End()
+
+	progress := newDataVolumeProgress(span)
+
+	ticker := time.NewTicker(dataVolumePollInterval)
+	defer ticker.Stop()
+
+	for {
+		pending, err := rc.pollDataVolumes(ctx, names, progress, true)
+		if err != nil {
+			span.RecordError(err)
+
+			return err
+		}
+
+		if pending == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			err = fmt.Errorf("%w: %d data volumes aren't populated", ErrWaitTimeout, pending)
+			span.RecordError(err)
+
+			return err
+		case <-ticker.C:
+		}
+	}
+}
+
+// createDataVolumesFirst creates the DataVolumes, owned by the runner pod
+// when it's known, and waits for them to be populated before it creates the
+// VMI and its runner-info Secret. The DataVolumes are then handed over to
+// the VMI, so they are garbage-collected along with it.
+func (rc *KubevirtRunner) createDataVolumesFirst(
+	ctx context.Context,
+	tracer trace.Tracer,
+	span trace.Span,
+	virtualMachineInstance *v1.VirtualMachineInstance,
+	dataVolumes []*v1beta1.DataVolume,
+	secret *k8scorev1.Secret,
+	deadline time.Time,
+) ([]string, error) {
+	dataVolumeNames, err := rc.createDataVolumes(ctx, tracer, dataVolumes, rc.podOwnerReference(), span)
+	if err == nil {
+		err = rc.waitForDataVolumes(ctx, tracer, dataVolumeNames, deadline)
+	}
+
+	if err == nil {
+		err = rc.startWithDataVolumes(ctx, tracer, span, virtualMachineInstance, dataVolumeNames, secret)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		rc.deleteDataVolumes(ctx, tracer, dataVolumes)
+
+		return nil, err
+	}
+
+	return dataVolumeNames, nil
+}
+
+// startWithDataVolumes creates the VMI and its runner-info Secret, then
+// hands the populated DataVolumes over to the VMI. The VMI is deleted again
+// when the Secret or the hand-over fails, so it doesn't outlive the run.
+func (rc *KubevirtRunner) startWithDataVolumes(
+	ctx context.Context,
+	tracer trace.Tracer,
+	span trace.Span,
+	virtualMachineInstance *v1.VirtualMachineInstance,
+	dataVolumeNames []string,
+	secret *k8scorev1.Secret,
+) error {
+	_, spanCreateVMI := tracer.Start(ctx, "CreateVMI",
+		trace.WithAttributes(
+			attribute.String("vmiName", virtualMachineInstance.Name),
+		),
+	)
+	defer spanCreateVMI.End()
+
+	vmi, err := rc.createVMI(ctx, virtualMachineInstance, span, spanCreateVMI)
+	if err != nil {
+		return err
+	}
+
+	err = rc.handOverDataVolumes(ctx, tracer, span, vmi, secret, dataVolumeNames)
+	if err != nil {
+		_ = rc.deleteVMI(ctx, span, vmi.Name, k8smetav1.DeleteOptions{})
+
+		return err
+	}
+
+	return nil
+}
+
+// handOverDataVolumes creates the runner-info Secret and makes the VMI the
+// owner of the DataVolumes.
+func (rc *KubevirtRunner) handOverDataVolumes(
+	ctx context.Context,
+	tracer trace.Tracer,
+	span trace.Span,
+	vmi *v1.VirtualMachineInstance,
+	secret *k8scorev1.Secret,
+	dataVolumeNames []string,
+) error {
+	owner := ownerReference(v1.VirtualMachineInstanceGroupVersionKind.Kind, vmi.Name, vmi.UID)
+
+	err := rc.createSecret(ctx, tracer, secret, owner, span)
+	if err != nil {
+		return err
+	}
+
+	patch, err := marshalJSON(map[string]any{"metadata": map[string]any{"ownerReferences": owner}})
+	if err != nil {
+		return fmt.Errorf("cannot encode the data volume owner patch: %w", err)
+	}
+
+	for _, name := range dataVolumeNames {
+		_, err = rc.virtClient.CdiClient().CdiV1beta1().DataVolumes(rc.namespace).Patch(
+			ctx, name, types.MergePatchType, patch, k8smetav1.PatchOptions{})
+		if err != nil {
+			return fmt.Errorf("cannot hand data volume %s over to %s: %w", name, vmi.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// deleteDataVolumes removes the DataVolumes created for a runner whose VMI
+// couldn't be started or given them, since no cleanup knows about them yet.
+func (rc *KubevirtRunner) deleteDataVolumes(
+	ctx context.Context,
+	tracer trace.Tracer,
+	dataVolumes []*v1beta1.DataVolume,
+) {
+	for _, dataVolume := range dataVolumes {
+		_ = rc.deleteDataVolume(ctx, tracer, dataVolume.Name, k8smetav1.DeleteOptions{})
+	}
+}
@@ -0,0 +1,250 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner_test
+
+import (
+	"context"
+	"time"
+
+	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	k8sv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime" //nolint:depguard // required by fake reactor signature
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing" //nolint:depguard // required by fake reactor signature
+	v1 "kubevirt.io/api/core/v1"
+	cdifake "kubevirt.io/client-go/containerizeddataimporter/fake"
+	"kubevirt.io/client-go/kubecli"
+	kubevirtfake "kubevirt.io/client-go/kubevirt/fake"
+	"kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+var _ = Describe("Data volumes", func() {
+	const (
+		vmTemplate = "vm-template"
+		runnerName = "runner-dv"
+		dataVolume = "boot-disk-" + runnerName
+	)
+
+	var (
+		virtClient    *kubecli.MockKubevirtClient
+		virtClientset *kubevirtfake.Clientset
+		cdiClientset  *cdifake.Clientset
+		k8sClientset  *k8sfake.Clientset
+	)
+
+	// reportPhase makes every get of the DataVolume return it in the phase.
+	reportPhase := func(phase v1beta1.DataVolumePhase, restarts int32, message string) {
+		cdiClientset.PrependReactor("get", "datavolumes",
+			func(k8stesting.Action) (bool, runtime.Object, error) {
+				dv := NewDataVolume(dataVolume)
+				dv.Status.Phase = phase
+				dv.Status.Progress = "42.00%"
+				dv.Status.RestartCount = restarts
+
+				if message != "" {
+					dv.Status.Conditions = []v1beta1.DataVolumeCondition{{
+						Type:    v1beta1.DataVolumeRunning,
+						Status:  k8sv1.ConditionFalse,
+						Reason:  "Error",
+						Message: message,
+					}}
+				}
+
+				return true, dv, nil
+			})
+	}
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		virtClient = kubecli.NewMockKubevirtClient(mockCtrl)
+		virtClientset = kubevirtfake.NewSimpleClientset(NewVirtualMachineWithDataVolumes(vmTemplate, "boot-disk"))
+		cdiClientset = cdifake.NewSimpleClientset()
+		k8sClientset = k8sfake.NewSimpleClientset()
+
+		virtClient.EXPECT().CdiClient().Return(cdiClientset).AnyTimes()
+		virtClient.EXPECT().CoreV1().Return(k8sClientset.CoreV1()).AnyTimes()
+		virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault)).AnyTimes()
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault)).AnyTimes()
+	})
+
+	AfterEach(func() {
+		runner.CancelAppContext()
+	})
+
+	Context("when waiting for the VMI", func() {
+		BeforeEach(func() {
+			Expect(virtClientset.Tracker().Add(NewVirtualMachineInstance(runnerName))).To(Succeed())
+			runner.NewAppContext(runnerName, []string{dataVolume}, "runner-info-"+runnerName)
+		})
+
+		It("fails as soon as a data volume has failed", func() {
+			reportPhase(v1beta1.Failed, 0, "Unable to connect to http data source")
+
+			err := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, time.Minute).
+				WaitForVirtualMachineInstance(context.TODO())
+
+			Expect(err).To(MatchError(runner.ErrDataVolumeFailed))
+			Expect(err).To(MatchError(ContainSubstring("Unable to connect to http data source")))
+		})
+
+		It("fails once the import was restarted too many times", func() {
+			reportPhase(v1beta1.ImportInProgress, 3, "Unable to connect to http data source")
+
+			err := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, time.Minute).
+				WaitForVirtualMachineInstance(context.TODO())
+
+			Expect(err).To(MatchError(runner.ErrDataVolumeFailed))
+			Expect(err).To(MatchError(ContainSubstring("failed 3 times")))
+		})
+
+		It("keeps waiting while the import is retried", func() {
+			reportPhase(v1beta1.ImportInProgress, 1, "Unable to connect to http data source")
+
+			ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+			defer cancel()
+
+			err := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, time.Minute).
+				WaitForVirtualMachineInstance(ctx)
+
+			Expect(err).To(MatchError(runner.ErrWaitTimeout))
+		})
+	})
+
+	Context("when the data volumes are created first", func() {
+		var karRunner runner.Runner
+
+		BeforeEach(func() {
+			karRunner = runner.NewRunner(k8sv1.NamespaceDefault, virtClient, time.Minute, runner.WithDataVolumesFirst())
+		})
+
+		It("creates the VMI once the data volumes are populated and hands them over", func() {
+			cdiClientset.PrependReactor("get", "datavolumes",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					_, err := virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).Get(
+						context.TODO(), runnerName, metav1.GetOptions{})
+					Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+
+					return false, nil, nil
+				})
+			reportPhase(v1beta1.Succeeded, 0, "")
+
+			err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerName,
+				"jitConfig", runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(runner.GetAppContext().GetDataVolumeNames()).To(ConsistOf(dataVolume))
+
+			dataVolumes, err := cdiClientset.CdiV1beta1().DataVolumes(k8sv1.NamespaceDefault).List(
+				context.TODO(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dataVolumes.Items).To(ConsistOf(HaveField("OwnerReferences", ConsistOf(SatisfyAll(
+				HaveField("Kind", v1.VirtualMachineInstanceGroupVersionKind.Kind),
+				HaveField("Name", runnerName),
+			)))))
+		})
+
+		It("deletes the data volumes without creating the VMI when one of them fails", func() {
+			reportPhase(v1beta1.Failed, 0, "Unable to connect to http data source")
+
+			err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerName,
+				"jitConfig", runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+
+			Expect(err).To(MatchError(runner.ErrDataVolumeFailed))
+			Expect(runner.HasAppContext()).To(BeFalse())
+
+			_, err = virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).Get(
+				context.TODO(), runnerName, metav1.GetOptions{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+
+			dataVolumes, err := cdiClientset.CdiV1beta1().DataVolumes(k8sv1.NamespaceDefault).List(
+				context.TODO(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dataVolumes.Items).To(BeEmpty())
+		})
+
+		It("deletes the populated data volumes when the VMI cannot be created", func() {
+			reportPhase(v1beta1.Succeeded, 0, "")
+			virtClientset.PrependReactor("create", "virtualmachineinstances",
+				func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, k8serrors.NewForbidden(v1.Resource("virtualmachineinstances"), runnerName, nil)
+				})
+
+			err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerName,
+				"jitConfig", runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+
+			Expect(k8serrors.IsForbidden(err)).To(BeTrue())
+			Expect(runner.HasAppContext()).To(BeFalse())
+
+			dataVolumes, err := cdiClientset.CdiV1beta1().DataVolumes(k8sv1.NamespaceDefault).List(
+				context.TODO(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dataVolumes.Items).To(BeEmpty())
+		})
+
+		It("deletes the VMI and the data volumes when the secret cannot be created", func() {
+			reportPhase(v1beta1.Succeeded, 0, "")
+			k8sClientset.PrependReactor("create", "secrets",
+				func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, k8serrors.NewForbidden(k8sv1.Resource("secrets"), runnerName, nil)
+				})
+
+			err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerName,
+				"jitConfig", runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+
+			Expect(k8serrors.IsForbidden(err)).To(BeTrue())
+			Expect(runner.HasAppContext()).To(BeFalse())
+
+			_, err = virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).Get(
+				context.TODO(), runnerName, metav1.GetOptions{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+
+			dataVolumes, err := cdiClientset.CdiV1beta1().DataVolumes(k8sv1.NamespaceDefault).List(
+				context.TODO(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dataVolumes.Items).To(BeEmpty())
+		})
+
+		It("waits for the VMI until the deadline shared with the data volumes", func() {
+			const waitTimeout = 500 * time.Millisecond
+
+			reportPhase(v1beta1.Succeeded, 0, "")
+
+			karRunner = runner.NewRunner(k8sv1.NamespaceDefault, virtClient, waitTimeout, runner.WithDataVolumesFirst())
+
+			err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerName,
+				"jitConfig", runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+			Expect(err).NotTo(HaveOccurred())
+
+			time.Sleep(waitTimeout)
+
+			start := time.Now()
+			err = karRunner.WaitForVirtualMachineInstance(context.TODO())
+
+			Expect(err).To(MatchError(runner.ErrWaitTimeout))
+			Expect(time.Since(start)).To(BeNumerically("<", waitTimeout/2))
+		})
+	})
+})
@@ -0,0 +1,172 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	k8scorev1 "k8s.io/api/core/v1"
+	v1 "kubevirt.io/api/core/v1"
+)
+
+// PhaseTimeouts bounds how long the VMI may take to reach each lifecycle
+// milestone, measured from the start of the wait. A zero value disables the
+// corresponding deadline.
+type PhaseTimeouts struct {
+	// Scheduled is the deadline for the VMI to reach the Scheduled phase.
+	Scheduled time.Duration
+	// Running is the deadline for the VMI to reach the Running phase.
+	Running time.Duration
+	// Ready is the deadline for the VMI to be Running and Ready.
+	Ready time.Duration
+	// Unschedulable is the grace period given to a VMI whose virt-launcher
+	// pod the scheduler reported as Unschedulable, measured from that report.
+	Unschedulable time.Duration
+}
+
+// phaseDeadlines holds the armed milestone timers of a single wait. Each
+// timer cancels the wait context with its milestone error when it fires.
+// The unschedulable timer is only armed while the VMI is reported as
+// Unschedulable.
+type phaseDeadlines struct {
+	scheduled     *time.Timer
+	running       *time.Timer
+	ready         *time.Timer
+	unschedulable *time.Timer
+
+	unschedulableGracePeriod time.Duration
+	cancel                   context.CancelCauseFunc
+}
+
+func armPhaseDeadlines(timeouts PhaseTimeouts, cancel context.CancelCauseFunc) *phaseDeadlines {
+	return &phaseDeadlines{
+		scheduled:                armDeadline(timeouts.Scheduled, ErrScheduleTimeout, cancel),
+		running:                  armDeadline(timeouts.Running, ErrBootTimeout, cancel),
+		ready:                    armDeadline(timeouts.Ready, ErrReadyTimeout, cancel),
+		unschedulableGracePeriod: timeouts.Unschedulable,
+		cancel:                   cancel,
+	}
+}
+
+func armDeadline(timeout time.Duration, cause error, cancel context.CancelCauseFunc) *time.Timer {
+	if timeout <= 0 {
+		return nil
+	}
+
+	return time.AfterFunc(timeout, func() { cancel(cause) })
+}
+
+// observe disarms the deadlines of every milestone the VMI has reached.
+func (d *phaseDeadlines) observe(phase v1.VirtualMachineInstancePhase, ready bool) {
+	if d == nil {
+		return
+	}
+
+	switch phase {
+	case v1.Succeeded, v1.Failed:
+		d.stop()
+	case v1.Running:
+		stopTimer(d.scheduled)
+		stopTimer(d.running)
+
+		if ready {
+			stopTimer(d.ready)
+		}
+	case v1.Scheduled:
+		stopTimer(d.scheduled)
+	case v1.VmPhaseUnset, v1.Pending, v1.Scheduling, v1.Unknown, v1.WaitingForSync:
+	}
+}
+
+// observeScheduling arms the unschedulable grace period when the VMI is first
+// reported as Unschedulable, and disarms it once the report goes away.
+func (d *phaseDeadlines) observeScheduling(unschedulable bool) {
+	if d == nil || d.unschedulableGracePeriod <= 0 {
+		return
+	}
+
+	switch {
+	case unschedulable && d.unschedulable == nil:
+		d.unschedulable = armDeadline(d.unschedulableGracePeriod, ErrUnschedulable, d.cancel)
+	case !unschedulable && d.unschedulable != nil:
+		d.unschedulable.Stop()
+		d.unschedulable = nil
+	}
+}
+
+func (d *phaseDeadlines) stop() {
+	if d == nil {
+		return
+	}
+
+	stopTimer(d.scheduled)
+	stopTimer(d.running)
+	stopTimer(d.ready)
+	stopTimer(d.unschedulable)
+}
+
+func stopTimer(timer *time.Timer) {
+	if timer != nil {
+		timer.Stop()
+	}
+}
+
+// waitTimeoutCause reports which deadline ended the wait: a missed phase
+// milestone, the unschedulable grace period or the overall wait timeout.
+// A DataVolume failure that cancelled the wait is returned as is.
+func waitTimeoutCause(ctx context.Context) error {
+	cause := context.Cause(ctx)
+	if errors.Is(cause, ErrDataVolumeFailed) {
+		return cause
+	}
+
+	for _, phaseErr := range []error{ErrScheduleTimeout, ErrBootTimeout, ErrReadyTimeout, ErrUnschedulable} {
+		if errors.Is(cause, phaseErr) {
+			return phaseErr
+		}
+	}
+
+	return ErrWaitTimeout
+}
+
+// isWaitTimeout reports whether err is one of the timeouts returned by
+// waitTimeoutCause.
+func isWaitTimeout(err error) bool {
+	return errors.Is(err, ErrWaitTimeout) ||
+		errors.Is(err, ErrScheduleTimeout) ||
+		errors.Is(err, ErrBootTimeout) ||
+		errors.Is(err, ErrReadyTimeout) ||
+		errors.Is(err, ErrUnschedulable)
+}
+
+// isVMIUnschedulable reports whether the scheduler couldn't place the
+// virt-launcher pod of the VMI, as propagated to its PodScheduled condition.
+func isVMIUnschedulable(vmi *v1.VirtualMachineInstance) bool {
+	for _, condition := range vmi.Status.Conditions {
+		if condition.Type == v1.VirtualMachineInstanceConditionType(k8scorev1.PodScheduled) &&
+			condition.Status == k8scorev1.ConditionFalse &&
+			condition.Reason == k8scorev1.PodReasonUnschedulable {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,170 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "kubevirt.io/api/core/v1"
+)
+
+// deletionPollInterval is how often pending deletions are checked. It is a
+// variable so tests don't have to wait for the production interval.
+//
+//nolint:gochecknoglobals
+var deletionPollInterval = 2 * time.Second
+
+// deletionTarget is a runner resource whose deletion is awaited.
+type deletionTarget struct {
+	kind   string
+	name   string
+	get    func(ctx context.Context) error
+	delete func(ctx context.Context, options k8smetav1.DeleteOptions) error
+}
+
+func (t deletionTarget) String() string {
+	return t.kind + " " + t.name
+}
+
+// deletionTargets returns the resources DeleteResources waits for. In
+// virtual machine mode the VMI is created by the VirtualMachine controller,
+// so both of them are awaited.
+func (rc *KubevirtRunner) deletionTargets(tracer trace.Tracer, span trace.Span, appCtx *AppContext) []deletionTarget {
+	vmiName := appCtx.GetVMIName()
+	targets := []deletionTarget{}
+
+	if rc.virtualMachine {
+		targets = append(targets, deletionTarget{
+			kind: v1.VirtualMachineGroupVersionKind.Kind,
+			name: vmiName,
+			get: func(ctx context.Context) error {
+				_, err := rc.virtClient.VirtualMachine(rc.namespace).Get(ctx, vmiName, k8smetav1.GetOptions{})
+
+				return err
+			},
+			delete: func(ctx context.Context, options k8smetav1.DeleteOptions) error {
+				return rc.deleteVirtualMachine(ctx, span, vmiName, options)
+			},
+		})
+	}
+
+	targets = append(targets, deletionTarget{
+		kind: v1.VirtualMachineInstanceGroupVersionKind.Kind,
+		name: vmiName,
+		get: func(ctx context.Context) error {
+			_, err := rc.virtClient.VirtualMachineInstance(rc.namespace).Get(ctx, vmiName, k8smetav1.GetOptions{})
+
+			return err
+		},
+		delete: func(ctx context.Context, options k8smetav1.DeleteOptions) error {
+			return rc.deleteVMI(ctx, span, vmiName, options)
+		},
+	})
+
+	for _, dataVolumeName := range appCtx.GetDataVolumeNames() {
+		targets = append(targets, deletionTarget{
+			kind: "DataVolume",
+			name: dataVolumeName,
+			get: func(ctx context.Context) error {
+				_, err := rc.virtClient.CdiClient().CdiV1beta1().DataVolumes(rc.namespace).Get(
+					ctx, dataVolumeName, k8smetav1.GetOptions{})
+
+				return err
+			},
+			delete: func(ctx context.Context, options k8smetav1.DeleteOptions) error {
+				return rc.deleteDataVolume(ctx, tracer, dataVolumeName, options)
+			},
+		})
+	}
+
+	return targets
+}
+
+// awaitDeletion polls the targets until all of them are gone, deleting the
+// remaining ones with a zero grace period once the graceful deletion stalls.
+func (rc *KubevirtRunner) awaitDeletion(ctx context.Context, span trace.Span, targets []deletionTarget) error {
+	ctx, cancel := context.WithTimeout(ctx, rc.deletionTimeout)
+	defer cancel()
+
+	log := utils.GetLogger()
+	forceAt := time.Now().Add(rc.forceDeleteAfter)
+	forced := false
+
+	ticker := time.NewTicker(deletionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		targets = pendingDeletions(ctx, targets)
+		if len(targets) == 0 {
+			span.AddEvent("resources_deleted")
+
+			return nil
+		}
+
+		if !forced && !time.Now().Before(forceAt) {
+			forced = true
+
+			for _, target := range targets {
+				log.Printf("Deletion of %s stalled, deleting it with a zero grace period\n", target)
+				span.AddEvent("force_delete", trace.WithAttributes(
+					attribute.String("kind", target.kind),
+					attribute.String("name", target.name),
+				))
+
+				_ = target.delete(ctx, k8smetav1.DeleteOptions{GracePeriodSeconds: new(int64(0))})
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			names := make([]string, 0, len(targets))
+			for _, target := range targets {
+				names = append(names, target.String())
+			}
+
+			return fmt.Errorf("%w: %s", ErrResourcesRemaining, strings.Join(names, ", "))
+		case <-ticker.C:
+		}
+	}
+}
+
+// pendingDeletions returns the targets that still exist. Lookup failures
+// other than NotFound keep the target pending.
+func pendingDeletions(ctx context.Context, targets []deletionTarget) []deletionTarget {
+	pending := targets[:0]
+
+	for _, target := range targets {
+		err := target.get(ctx)
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+
+		pending = append(pending, target)
+	}
+
+	return pending
+}
@@ -0,0 +1,272 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	k8scorev1 "k8s.io/api/core/v1"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	v1 "kubevirt.io/api/core/v1"
+	"kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+const (
+	// diagnosticsTimeout bounds the time spent collecting a diagnostics bundle.
+	diagnosticsTimeout = 30 * time.Second
+	// launcherLogTailLines is the number of compute container log lines kept.
+	launcherLogTailLines = 500
+	launcherContainer    = "compute"
+	diagnosticsFileMode  = 0o600
+)
+
+// DiagnosticsBundle is the state of a failed runner, written as JSON to the
+// diagnostics directory.
+type DiagnosticsBundle struct {
+	CollectedAt            k8smetav1.Time                    `json:"collectedAt"`
+	Reason                 string                            `json:"reason"`
+	Summary                string                            `json:"summary"`
+	VirtualMachineInstance *v1.VirtualMachineInstance        `json:"virtualMachineInstance,omitempty"`
+	Events                 []k8scorev1.Event                 `json:"events,omitempty"`
+	LauncherPods           []LauncherPodDiagnostics          `json:"launcherPods,omitempty"`
+	DataVolumes            []v1beta1.DataVolume              `json:"dataVolumes,omitempty"`
+	PersistentVolumeClaims []k8scorev1.PersistentVolumeClaim `json:"persistentVolumeClaims,omitempty"`
+	CollectionErrors       []string                          `json:"collectionErrors,omitempty"`
+}
+
+// LauncherPodDiagnostics holds the status and the compute container logs of
+// a virt-launcher pod.
+type LauncherPodDiagnostics struct {
+	Name   string              `json:"name"`
+	Status k8scorev1.PodStatus `json:"status"`
+	Logs   string              `json:"logs,omitempty"`
+}
+
+// WithDiagnosticsDir writes a diagnostics bundle to dir when the VMI fails or
+// the wait times out.
+func WithDiagnosticsDir(dir string) Option {
+	return func(rc *KubevirtRunner) {
+		rc.diagnosticsDir = dir
+	}
+}
+
+// diagnoseFailure collects what is known about the failed VMI, logs the most
+// likely root cause and writes the diagnostics bundle when a directory is
+// configured. It returns cause wrapped in a VMIFailureError.
+func (rc *KubevirtRunner) diagnoseFailure(ctx context.Context, vmiName string, cause error) error {
+	tracer := otel.Tracer(tracerName)
+
+	ctx, cancel := context.WithTimeout(ctx, diagnosticsTimeout)
+	defer cancel()
+
+	ctx, span := tracer.Start(ctx, "DiagnoseFailure",
+		trace.WithAttributes(attribute.String("vmiName", vmiName)),
+	)
+	defer span.End()
+
+	bundle := rc.collectDiagnostics(ctx, vmiName, cause)
+	failure := newVMIFailureError(cause, vmiName, bundle)
+	bundle.Summary = summarizeDiagnostics(failure, bundle.Events)
+
+	utils.GetLogger().Printf("Runner failure summary: %s\n", bundle.Summary)
+	span.SetAttributes(attribute.StringSlice("reasons", failure.Reasons()))
+
+	if rc.diagnosticsDir != "" {
+		rc.writeDiagnostics(span, vmiName, bundle)
+	}
+
+	return failure
+}
+
+// writeDiagnostics writes the bundle to the diagnostics directory. Failures
+// are only logged since they mustn't hide the runner failure.
+func (rc *KubevirtRunner) writeDiagnostics(span trace.Span, vmiName string, bundle DiagnosticsBundle) {
+	log := utils.GetLogger()
+
+	data, err := marshalJSON(bundle)
+	if err != nil {
+		span.RecordError(err)
+		log.Warnf("failed to encode the diagnostics bundle: %v", err)
+
+		return
+	}
+
+	path := filepath.Join(rc.diagnosticsDir, vmiName+"-diagnostics.json")
+
+	err = os.WriteFile(path, data, diagnosticsFileMode)
+	if err != nil {
+		span.RecordError(err)
+		log.Warnf("failed to write the diagnostics bundle: %v", err)
+
+		return
+	}
+
+	log.Printf("Diagnostics bundle written to %s\n", path)
+}
+
+// collectDiagnostics gathers everything it can about the VMI, recording the
+// lookups that failed in the bundle instead of giving up.
+func (rc *KubevirtRunner) collectDiagnostics(ctx context.Context, vmiName string, cause error) DiagnosticsBundle {
+	bundle := DiagnosticsBundle{
+		CollectedAt: k8smetav1.Now(),
+		Reason:      cause.Error(),
+	}
+
+	recordErr := func(what string, err error) {
+		bundle.CollectionErrors = append(bundle.CollectionErrors, fmt.Sprintf("%s: %v", what, err))
+	}
+
+	involved := []string{vmiName}
+
+	vmi, err := rc.virtClient.VirtualMachineInstance(rc.namespace).Get(ctx, vmiName, k8smetav1.GetOptions{})
+	if err != nil {
+		recordErr("get virtual machine instance", err)
+	} else {
+		bundle.VirtualMachineInstance = vmi
+		bundle.LauncherPods = rc.collectLauncherPods(ctx, vmi, recordErr)
+
+		for _, pod := range bundle.LauncherPods {
+			involved = append(involved, pod.Name)
+		}
+	}
+
+	if HasAppContext() {
+		for _, name := range GetAppContext().GetDataVolumeNames() {
+			involved = append(involved, name)
+			rc.collectDataVolume(ctx, name, &bundle, recordErr)
+		}
+	}
+
+	for _, name := range involved {
+		bundle.Events = append(bundle.Events, rc.collectEvents(ctx, name, recordErr)...)
+	}
+
+	// The summary relies on the last warning across all the involved objects.
+	slices.SortStableFunc(bundle.Events, func(a, b k8scorev1.Event) int {
+		return a.LastTimestamp.Time.Compare(b.LastTimestamp.Time)
+	})
+
+	return bundle
+}
+
+// collectEvents lists the events of a single object, so the bundle never
+// carries the events of other runners sharing the namespace.
+func (rc *KubevirtRunner) collectEvents(
+	ctx context.Context,
+	name string,
+	recordErr func(string, error),
+) []k8scorev1.Event {
+	events, err := rc.virtClient.CoreV1().Events(rc.namespace).List(ctx, k8smetav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.name", name).String(),
+	})
+	if err != nil {
+		recordErr("list events of "+name, err)
+
+		return nil
+	}
+
+	return events.Items
+}
+
+func (rc *KubevirtRunner) collectLauncherPods(
+	ctx context.Context,
+	vmi *v1.VirtualMachineInstance,
+	recordErr func(string, error),
+) []LauncherPodDiagnostics {
+	pods, err := rc.virtClient.CoreV1().Pods(rc.namespace).List(ctx, k8smetav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{v1.CreatedByLabel: string(vmi.UID)}).String(),
+	})
+	if err != nil {
+		recordErr("list virt-launcher pods", err)
+
+		return nil
+	}
+
+	launcherPods := make([]LauncherPodDiagnostics, 0, len(pods.Items))
+
+	for _, pod := range pods.Items {
+		launcherPod := LauncherPodDiagnostics{Name: pod.Name, Status: pod.Status}
+
+		// The logs are only kept in the bundle, so skip them when it isn't written.
+		if rc.diagnosticsDir != "" {
+			logs, err := rc.virtClient.CoreV1().Pods(rc.namespace).GetLogs(pod.Name, &k8scorev1.PodLogOptions{
+				Container: launcherContainer,
+				TailLines: new(int64(launcherLogTailLines)),
+			}).DoRaw(ctx)
+			if err != nil {
+				recordErr("get logs of "+pod.Name, err)
+			}
+
+			launcherPod.Logs = string(logs)
+		}
+
+		launcherPods = append(launcherPods, launcherPod)
+	}
+
+	return launcherPods
+}
+
+func (rc *KubevirtRunner) collectDataVolume(
+	ctx context.Context,
+	name string,
+	bundle *DiagnosticsBundle,
+	recordErr func(string, error),
+) {
+	dataVolume, err := rc.virtClient.CdiClient().CdiV1beta1().DataVolumes(rc.namespace).Get(
+		ctx, name, k8smetav1.GetOptions{})
+	if err != nil {
+		recordErr("get data volume "+name, err)
+	} else {
+		bundle.DataVolumes = append(bundle.DataVolumes, *dataVolume)
+	}
+
+	// CDI names the PVC of a DataVolume after it.
+	pvc, err := rc.virtClient.CoreV1().PersistentVolumeClaims(rc.namespace).Get(ctx, name, k8smetav1.GetOptions{})
+	if err != nil {
+		recordErr("get persistent volume claim "+name, err)
+	} else {
+		bundle.PersistentVolumeClaims = append(bundle.PersistentVolumeClaims, *pvc)
+	}
+}
+
+// summarizeDiagnostics describes the failure in a single line, followed by the
+// last warning event of the involved objects.
+func summarizeDiagnostics(failure *VMIFailureError, events []k8scorev1.Event) string {
+	summary := failure.Error()
+
+	for _, event := range slices.Backward(events) {
+		if event.Type == k8scorev1.EventTypeWarning {
+			return fmt.Sprintf("%s; last warning: %s %s: %s",
+				summary, event.InvolvedObject.Name, event.Reason, event.Message)
+		}
+	}
+
+	return summary
+}
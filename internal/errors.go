@@ -32,4 +32,61 @@ var (
 
 	// ErrRunnerFailed indicates that the runner has failed during its execution.
 	ErrRunnerFailed = errors.New("runner has failed")
+
+	// ErrTemplateNotFound indicates that the virtual machine template doesn't exist.
+	ErrTemplateNotFound = errors.New("virtual machine template not found")
+
+	// ErrWaitTimeout indicates that the runner didn't reach a terminal phase in time.
+	ErrWaitTimeout = errors.New("timeout while waiting for the virtual machine instance")
+
+	// ErrScheduleTimeout indicates that the virtual machine instance wasn't scheduled in time,
+	// usually because the cluster has no capacity for it.
+	ErrScheduleTimeout = errors.New("timeout while waiting for the virtual machine instance to be scheduled")
+
+	// ErrBootTimeout indicates that the virtual machine instance didn't start running in time.
+	ErrBootTimeout = errors.New("timeout while waiting for the virtual machine instance to be running")
+
+	// ErrReadyTimeout indicates that the virtual machine instance didn't become ready in time.
+	ErrReadyTimeout = errors.New("timeout while waiting for the virtual machine instance to be ready")
+
+	// ErrUnschedulable indicates that the scheduler kept reporting the virtual machine instance
+	// as Unschedulable for longer than the configured grace period.
+	ErrUnschedulable = errors.New("virtual machine instance is unschedulable")
+
+	// ErrNoWarmRunner indicates that the pool has no Running and Ready runner to claim.
+	ErrNoWarmRunner = errors.New("no warm runner available")
+
+	// ErrPoolUnsupported indicates that warm pools aren't available in the VirtualMachine mode.
+	ErrPoolUnsupported = errors.New("warm pools require the VirtualMachineInstance mode")
+
+	// ErrInvalidPoolSpec indicates that the name or the size of a pool is invalid.
+	ErrInvalidPoolSpec = errors.New("invalid pool")
+
+	// ErrDataVolumeFailed indicates that a runner DataVolume couldn't be imported, cloned or uploaded.
+	ErrDataVolumeFailed = errors.New("runner data volume failed")
+
+	// ErrInvalidTemplateSource indicates that the template source is unknown or holds a malformed template.
+	ErrInvalidTemplateSource = errors.New("invalid template source")
+
+	// ErrSnapshotNotReady indicates that a snapshot referenced by the template can't be restored yet.
+	ErrSnapshotNotReady = errors.New("snapshot isn't ready to be restored")
+
+	// ErrInvalidResourceOverride indicates that a CPU, memory or disk size override is malformed
+	// or outside of the configured limits.
+	ErrInvalidResourceOverride = errors.New("invalid resource override")
+
+	// ErrResourceConflict indicates that a runner resource created by a retried request belongs to another runner.
+	ErrResourceConflict = errors.New("runner resource already exists for another runner")
+
+	// ErrResourcesRemaining indicates that runner resources still existed when the cleanup timed out.
+	ErrResourcesRemaining = errors.New("runner resources still exist after cleanup")
+
+	// ErrShutdownTimeout indicates that the guest didn't power off within the shutdown grace period.
+	ErrShutdownTimeout = errors.New("timeout while waiting for the guest to shut down")
+
+	// ErrInvalidResourceMetadata indicates that an extra label or annotation is malformed.
+	ErrInvalidResourceMetadata = errors.New("invalid resource metadata")
+
+	// ErrInvalidDiskRestoreStrategy indicates that the disk restore strategy is unknown.
+	ErrInvalidDiskRestoreStrategy = errors.New("invalid disk restore strategy")
 )
@@ -0,0 +1,155 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"sync/atomic"
+	"time"
+
+	k8scorev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	eventComponent = "kubevirt-actions-runner"
+	// eventFlushTimeout bounds how long Shutdown waits for the recorded events
+	// to be written.
+	eventFlushTimeout = 5 * time.Second
+	// eventFlushInterval is the interval between two checks of the written
+	// events.
+	eventFlushInterval = 10 * time.Millisecond
+)
+
+// Reasons of the events recorded on the runner pod.
+const (
+	EventReasonCreated         = "Created"
+	EventReasonFailedCreate    = "FailedCreate"
+	EventReasonPhaseTransition = "PhaseTransition"
+	EventReasonReady           = "Ready"
+	EventReasonSucceeded       = "Succeeded"
+	EventReasonFailed          = "Failed"
+	EventReasonTimeout         = "Timeout"
+	EventReasonShuttingDown    = "ShuttingDown"
+	EventReasonPreserved       = "Preserved"
+	EventReasonClaimed         = "Claimed"
+	EventReasonDeleted         = "Deleted"
+	EventReasonFailedDelete    = "FailedDelete"
+)
+
+// PodEventRecorder records the runner lifecycle as Kubernetes Events on the
+// pod running kar, so `kubectl describe pod` shows what happened. A nil
+// recorder discards every event.
+type PodEventRecorder struct {
+	broadcaster record.EventBroadcaster
+	recorder    record.EventRecorder
+	sink        *countingEventSink
+	pod         *k8scorev1.Pod
+	recorded    atomic.Int64
+}
+
+// countingEventSink counts the events written by the broadcaster, so
+// Shutdown knows when the recorded events reached the API server.
+type countingEventSink struct {
+	record.EventSink
+
+	written atomic.Int64
+}
+
+func (s *countingEventSink) Create(event *k8scorev1.Event) (*k8scorev1.Event, error) {
+	return s.count(s.EventSink.Create(event))
+}
+
+func (s *countingEventSink) Update(event *k8scorev1.Event) (*k8scorev1.Event, error) {
+	return s.count(s.EventSink.Update(event))
+}
+
+func (s *countingEventSink) Patch(oldEvent *k8scorev1.Event, data []byte) (*k8scorev1.Event, error) {
+	return s.count(s.EventSink.Patch(oldEvent, data))
+}
+
+func (s *countingEventSink) count(event *k8scorev1.Event, err error) (*k8scorev1.Event, error) {
+	if err == nil {
+		s.written.Add(1)
+	}
+
+	return event, err
+}
+
+// NewPodEventRecorder starts sending events about the runner pod to its
+// namespace.
+func NewPodEventRecorder(coreClient typedcorev1.CoreV1Interface, pod *k8scorev1.Pod) *PodEventRecorder {
+	broadcaster := record.NewBroadcaster()
+	sink := &countingEventSink{EventSink: &typedcorev1.EventSinkImpl{Interface: coreClient.Events(pod.Namespace)}}
+	broadcaster.StartRecordingToSink(sink)
+
+	return &PodEventRecorder{
+		broadcaster: broadcaster,
+		recorder:    broadcaster.NewRecorder(scheme.Scheme, k8scorev1.EventSource{Component: eventComponent}),
+		sink:        sink,
+		pod:         pod,
+	}
+}
+
+// Normalf records an informational event on the runner pod.
+func (r *PodEventRecorder) Normalf(reason, messageFmt string, args ...any) {
+	r.eventf(k8scorev1.EventTypeNormal, reason, messageFmt, args...)
+}
+
+// Warningf records a warning event on the runner pod.
+func (r *PodEventRecorder) Warningf(reason, messageFmt string, args ...any) {
+	r.eventf(k8scorev1.EventTypeWarning, reason, messageFmt, args...)
+}
+
+func (r *PodEventRecorder) eventf(eventType, reason, messageFmt string, args ...any) {
+	if r == nil {
+		return
+	}
+
+	r.recorded.Add(1)
+	r.recorder.Eventf(r.pod, eventType, reason, messageFmt, args...)
+}
+
+// Shutdown waits up to eventFlushTimeout for the recorded events to be
+// written, then stops the event broadcaster. Events that are still queued
+// after that, or that the broadcaster drops as duplicates, are lost.
+func (r *PodEventRecorder) Shutdown() {
+	if r == nil {
+		return
+	}
+
+	r.waitForWrittenEvents()
+	r.broadcaster.Shutdown()
+}
+
+func (r *PodEventRecorder) waitForWrittenEvents() {
+	ticker := time.NewTicker(eventFlushInterval)
+	defer ticker.Stop()
+
+	deadline := time.After(eventFlushTimeout)
+
+	for r.sink.written.Load() < r.recorded.Load() {
+		select {
+		case <-deadline:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,171 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	k8scorev1 "k8s.io/api/core/v1"
+	v1 "kubevirt.io/api/core/v1"
+	"kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+// dataVolumeErrorReason is the reason CDI sets on the Running condition of a
+// DataVolume whose import, clone or upload failed.
+const dataVolumeErrorReason = "Error"
+
+// VMIFailureError explains why the runner virtual machine instance failed or
+// didn't reach a terminal phase in time. It wraps ErrRunnerFailed or one of
+// the wait timeouts, so callers can keep matching those with errors.Is.
+type VMIFailureError struct {
+	cause error
+
+	// VMIName is the name of the failed virtual machine instance.
+	VMIName string
+	// Phase is the last observed phase of the virtual machine instance.
+	Phase v1.VirtualMachineInstancePhase
+	// Conditions lists the VMI conditions that weren't met.
+	Conditions []string
+	// LauncherTermination lists why the virt-launcher pod or its containers terminated.
+	LauncherTermination []string
+	// Unschedulable lists the scheduler messages explaining why the pod couldn't be placed.
+	Unschedulable []string
+	// DataVolumeErrors lists the DataVolume import, clone or upload errors.
+	DataVolumeErrors []string
+}
+
+func (e *VMIFailureError) Error() string {
+	reasons := e.Reasons()
+	if len(reasons) == 0 {
+		return e.cause.Error()
+	}
+
+	return fmt.Sprintf("%v: %s", e.cause, strings.Join(reasons, "; "))
+}
+
+func (e *VMIFailureError) Unwrap() error {
+	return e.cause
+}
+
+// Reasons returns every known reason of the failure, scheduling problems first.
+func (e *VMIFailureError) Reasons() []string {
+	reasons := make([]string, 0,
+		1+len(e.Unschedulable)+len(e.DataVolumeErrors)+len(e.LauncherTermination)+len(e.Conditions))
+
+	if e.Phase != "" {
+		reasons = append(reasons, "phase "+string(e.Phase))
+	}
+
+	reasons = append(reasons, e.Unschedulable...)
+	reasons = append(reasons, e.DataVolumeErrors...)
+	reasons = append(reasons, e.LauncherTermination...)
+
+	return append(reasons, e.Conditions...)
+}
+
+// newVMIFailureError extracts the failure reasons of a VMI from what has been
+// collected about it.
+func newVMIFailureError(cause error, vmiName string, bundle DiagnosticsBundle) *VMIFailureError {
+	failure := &VMIFailureError{cause: cause, VMIName: vmiName}
+
+	if vmi := bundle.VirtualMachineInstance; vmi != nil {
+		failure.Phase = vmi.Status.Phase
+
+		for _, condition := range vmi.Status.Conditions {
+			switch {
+			case condition.Reason == k8scorev1.PodReasonUnschedulable:
+				failure.addUnschedulable(condition.Message)
+			case condition.Status == k8scorev1.ConditionFalse && condition.Message != "":
+				failure.Conditions = append(failure.Conditions,
+					fmt.Sprintf("%s: %s", condition.Type, conditionDetail(condition.Reason, condition.Message)))
+			}
+		}
+	}
+
+	for _, pod := range bundle.LauncherPods {
+		failure.addLauncherPod(pod)
+	}
+
+	for _, dataVolume := range bundle.DataVolumes {
+		failure.addDataVolume(dataVolume)
+	}
+
+	return failure
+}
+
+func (e *VMIFailureError) addUnschedulable(message string) {
+	message = "unschedulable: " + message
+	if !slices.Contains(e.Unschedulable, message) {
+		e.Unschedulable = append(e.Unschedulable, message)
+	}
+}
+
+func (e *VMIFailureError) addLauncherPod(pod LauncherPodDiagnostics) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == k8scorev1.PodScheduled && condition.Reason == k8scorev1.PodReasonUnschedulable {
+			e.addUnschedulable(condition.Message)
+		}
+	}
+
+	if pod.Status.Reason != "" {
+		e.LauncherTermination = append(e.LauncherTermination,
+			fmt.Sprintf("pod %s: %s", pod.Name, conditionDetail(pod.Status.Reason, pod.Status.Message)))
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		terminated := status.State.Terminated
+		if terminated == nil || terminated.ExitCode == 0 {
+			continue
+		}
+
+		e.LauncherTermination = append(e.LauncherTermination,
+			fmt.Sprintf("container %s of pod %s: %s (exit code %d)",
+				status.Name, pod.Name, conditionDetail(terminated.Reason, terminated.Message), terminated.ExitCode))
+	}
+}
+
+func (e *VMIFailureError) addDataVolume(dataVolume v1beta1.DataVolume) {
+	for _, condition := range dataVolume.Status.Conditions {
+		if condition.Type == v1beta1.DataVolumeRunning && condition.Reason == dataVolumeErrorReason {
+			e.DataVolumeErrors = append(e.DataVolumeErrors,
+				fmt.Sprintf("data volume %s: %s", dataVolume.Name, condition.Message))
+
+			return
+		}
+	}
+
+	if dataVolume.Status.Phase == v1beta1.Failed {
+		e.DataVolumeErrors = append(e.DataVolumeErrors, fmt.Sprintf("data volume %s has failed", dataVolume.Name))
+	}
+}
+
+// conditionDetail joins a machine-readable reason with its human-readable
+// message, skipping whichever is empty.
+func conditionDetail(reason, message string) string {
+	switch {
+	case reason == "":
+		return message
+	case message == "":
+		return reason
+	default:
+		return reason + ": " + message
+	}
+}
@@ -0,0 +1,305 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	v1 "kubevirt.io/api/core/v1"
+)
+
+// GarbageCollector reaps the runner resources left behind by runner pods
+// that never ran their cleanup.
+type GarbageCollector interface {
+	CollectGarbage(ctx context.Context, opts GCOptions) ([]GCResult, error)
+}
+
+var _ GarbageCollector = (*KubevirtRunner)(nil)
+
+// GCOptions configures a garbage collection pass.
+type GCOptions struct {
+	// MaxAge also reaps resources older than it, even when their runner pod
+	// still exists. Zero disables the age check.
+	MaxAge time.Duration
+	// DryRun only reports the resources that would be deleted.
+	DryRun bool
+}
+
+// GCResult describes an orphaned runner resource found by CollectGarbage.
+type GCResult struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Age       string `json:"age"`
+	Reason    string `json:"reason"`
+	Deleted   bool   `json:"deleted"`
+	Error     string `json:"error,omitempty"`
+}
+
+// gcCandidate is a resource managed by kar along with the function that
+// deletes it.
+type gcCandidate struct {
+	kind   string
+	meta   k8smetav1.ObjectMeta
+	delete func(ctx context.Context) error
+}
+
+// CollectGarbage lists the VirtualMachines, VMIs and DataVolumes created by
+// kar in the runner namespace and deletes the ones whose runner pod no longer
+// exists or that exceed the maximum age.
+func (rc *KubevirtRunner) CollectGarbage(ctx context.Context, opts GCOptions) ([]GCResult, error) {
+	tracer := otel.Tracer(tracerName)
+
+	ctx, span := tracer.Start(ctx, "CollectGarbage",
+		trace.WithAttributes(
+			attribute.String("namespace", rc.namespace),
+			attribute.Bool("dryRun", opts.DryRun),
+		),
+	)
+	defer span.End()
+
+	candidates, err := rc.listGCCandidates(ctx, tracer, span)
+	if err != nil {
+		span.RecordError(err)
+
+		return nil, err
+	}
+
+	log := utils.GetLogger()
+	podExists := map[string]bool{}
+	results := make([]GCResult, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		reason, orphaned, err := rc.orphanReason(ctx, candidate.meta, opts.MaxAge, podExists)
+		if err != nil {
+			span.RecordError(err)
+
+			return results, err
+		}
+
+		if !orphaned {
+			continue
+		}
+
+		result := GCResult{
+			Kind:      candidate.kind,
+			Namespace: candidate.meta.Namespace,
+			Name:      candidate.meta.Name,
+			Age:       time.Since(candidate.meta.CreationTimestamp.Time).Round(time.Second).String(),
+			Reason:    reason,
+		}
+
+		if !opts.DryRun {
+			log.Printf("Deleting orphaned %s %s: %s\n", candidate.kind, candidate.meta.Name, reason)
+
+			err := candidate.delete(ctx)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				result.Error = err.Error()
+			} else {
+				result.Deleted = true
+			}
+		}
+
+		span.AddEvent("orphan_found", trace.WithAttributes(
+			attribute.String("kind", result.Kind),
+			attribute.String("name", result.Name),
+			attribute.Bool("deleted", result.Deleted),
+		))
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (rc *KubevirtRunner) listGCCandidates(
+	ctx context.Context,
+	tracer trace.Tracer,
+	span trace.Span,
+) ([]gcCandidate, error) {
+	listOptions := k8smetav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{ManagedByLabel: ManagedByValue}).String(),
+	}
+
+	var candidates []gcCandidate
+
+	vms, err := rc.virtClient.VirtualMachine(rc.namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list runner virtual machines: %w", err)
+	}
+
+	for _, vm := range vms.Items {
+		candidates = append(candidates, gcCandidate{
+			kind: v1.VirtualMachineGroupVersionKind.Kind,
+			meta: vm.ObjectMeta,
+			delete: func(ctx context.Context) error {
+				return rc.deleteVirtualMachine(ctx, span, vm.Name, k8smetav1.DeleteOptions{})
+			},
+		})
+	}
+
+	vmis, err := rc.virtClient.VirtualMachineInstance(rc.namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list runner instances: %w", err)
+	}
+
+	for _, vmi := range vmis.Items {
+		candidates = append(candidates, gcCandidate{
+			kind: v1.VirtualMachineInstanceGroupVersionKind.Kind,
+			meta: vmi.ObjectMeta,
+			delete: func(ctx context.Context) error {
+				return rc.deleteVMI(ctx, span, vmi.Name, k8smetav1.DeleteOptions{})
+			},
+		})
+	}
+
+	dataVolumes, err := rc.virtClient.CdiClient().CdiV1beta1().DataVolumes(rc.namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list runner data volumes: %w", err)
+	}
+
+	for _, dataVolume := range dataVolumes.Items {
+		candidates = append(candidates, gcCandidate{
+			kind: "DataVolume",
+			meta: dataVolume.ObjectMeta,
+			delete: func(ctx context.Context) error {
+				return rc.deleteDataVolume(ctx, tracer, dataVolume.Name, k8smetav1.DeleteOptions{})
+			},
+		})
+	}
+
+	return candidates, nil
+}
+
+// orphanReason reports why a resource is orphaned. Preserved resources are
+// reaped once they expire, resources owned by a VirtualMachine or VMI that
+// still exists are left to their owner, and resources without the runner pod
+// annotation can only be reaped by age. Warm pool runners are left to their
+// pool by the age check.
+func (rc *KubevirtRunner) orphanReason(
+	ctx context.Context,
+	meta k8smetav1.ObjectMeta,
+	maxAge time.Duration,
+	podExists map[string]bool,
+) (string, bool, error) {
+	if expiry, preserved := preservedUntil(meta); preserved {
+		if time.Now().Before(expiry) {
+			return "", false, nil
+		}
+
+		return fmt.Sprintf("preserved until %s", expiry.Format(time.RFC3339)), true, nil
+	}
+
+	owned, err := rc.ownerExists(ctx, meta)
+	if err != nil || owned {
+		return "", false, err
+	}
+
+	if age, aged := runnerAge(meta); maxAge > 0 && aged && age > maxAge {
+		return fmt.Sprintf("older than %s", maxAge), true, nil
+	}
+
+	pod, found := meta.Annotations[RunnerPodAnnotation]
+	if !found {
+		return "", false, nil
+	}
+
+	exists, checked := podExists[pod]
+	if !checked {
+		podNamespace, podName, _ := strings.Cut(pod, "/")
+
+		_, err := rc.virtClient.CoreV1().Pods(podNamespace).Get(ctx, podName, k8smetav1.GetOptions{})
+
+		switch {
+		case err == nil:
+			exists = true
+		case k8serrors.IsNotFound(err):
+			exists = false
+		default:
+			return "", false, fmt.Errorf("cannot check runner pod %s: %w", pod, err)
+		}
+
+		podExists[pod] = exists
+	}
+
+	if exists {
+		return "", false, nil
+	}
+
+	return fmt.Sprintf("runner pod %s not found", pod), true, nil
+}
+
+// runnerAge returns how long a resource has been running a job. It returns
+// false for the warm pool runners, which don't run any, and a claimed runner
+// is aged from its claim rather than from its creation.
+func runnerAge(meta k8smetav1.ObjectMeta) (time.Duration, bool) {
+	if meta.Labels[PoolStateLabel] == PoolStateWarm {
+		return 0, false
+	}
+
+	claimedAt, err := time.Parse(time.RFC3339, meta.Annotations[ClaimedAtAnnotation])
+	if err == nil {
+		return time.Since(claimedAt), true
+	}
+
+	return time.Since(meta.CreationTimestamp.Time), true
+}
+
+// ownerExists reports whether a VirtualMachine or VMI owning the resource is
+// still around. The DataVolumes of a preserved VirtualMachine only carry the
+// annotations of the runner, so they must follow the fate of their owner.
+func (rc *KubevirtRunner) ownerExists(ctx context.Context, meta k8smetav1.ObjectMeta) (bool, error) {
+	for _, owner := range meta.OwnerReferences {
+		var (
+			ownerMeta k8smetav1.Object
+			err       error
+		)
+
+		switch owner.Kind {
+		case v1.VirtualMachineGroupVersionKind.Kind:
+			ownerMeta, err = rc.virtClient.VirtualMachine(rc.namespace).Get(ctx, owner.Name, k8smetav1.GetOptions{})
+		case v1.VirtualMachineInstanceGroupVersionKind.Kind:
+			ownerMeta, err = rc.virtClient.VirtualMachineInstance(rc.namespace).Get(
+				ctx, owner.Name, k8smetav1.GetOptions{})
+		default:
+			continue
+		}
+
+		switch {
+		case k8serrors.IsNotFound(err):
+			continue
+		case err != nil:
+			return false, fmt.Errorf("cannot check owner %s %s: %w", owner.Kind, owner.Name, err)
+		case ownerMeta.GetUID() == owner.UID:
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,233 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner_test
+
+import (
+	"context"
+	"time"
+
+	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	k8sv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime" //nolint:depguard // required by fake reactor signature
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing" //nolint:depguard // required by fake reactor signature
+	v1 "kubevirt.io/api/core/v1"
+	cdifake "kubevirt.io/client-go/containerizeddataimporter/fake"
+	"kubevirt.io/client-go/kubecli"
+	kubevirtfake "kubevirt.io/client-go/kubevirt/fake"
+)
+
+var _ = Describe("Garbage collector", func() {
+	const (
+		livePod     = "live-pod"
+		orphanedVMI = "runner-orphaned"
+		liveVMI     = "runner-live"
+		orphanedDV  = "dv-orphaned"
+	)
+
+	var virtClientset *kubevirtfake.Clientset
+
+	var cdiClientset *cdifake.Clientset
+
+	var k8sClientset *k8sfake.Clientset
+
+	var collector runner.GarbageCollector
+
+	managed := func(meta *metav1.ObjectMeta, pod string, age time.Duration) {
+		meta.Labels = map[string]string{runner.ManagedByLabel: runner.ManagedByValue}
+		meta.Annotations = map[string]string{runner.RunnerPodAnnotation: k8sv1.NamespaceDefault + "/" + pod}
+		meta.CreationTimestamp = metav1.NewTime(time.Now().Add(-age))
+	}
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		virtClient := kubecli.NewMockKubevirtClient(mockCtrl)
+
+		orphaned := NewVirtualMachineInstance(orphanedVMI)
+		managed(&orphaned.ObjectMeta, "gone-pod", time.Hour)
+
+		live := NewVirtualMachineInstance(liveVMI)
+		managed(&live.ObjectMeta, livePod, 2*time.Hour)
+
+		dataVolume := NewDataVolume(orphanedDV)
+		managed(&dataVolume.ObjectMeta, "gone-pod", time.Hour)
+
+		unmanaged := NewVirtualMachineInstance("unmanaged")
+		unmanaged.CreationTimestamp = metav1.NewTime(time.Now().Add(-48 * time.Hour))
+
+		virtClientset = kubevirtfake.NewSimpleClientset(orphaned, live, unmanaged)
+		cdiClientset = cdifake.NewSimpleClientset(dataVolume)
+		k8sClientset = k8sfake.NewSimpleClientset(&k8sv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: livePod, Namespace: k8sv1.NamespaceDefault},
+		})
+
+		virtClient.EXPECT().CdiClient().Return(cdiClientset).AnyTimes()
+		virtClient.EXPECT().CoreV1().Return(k8sClientset.CoreV1()).AnyTimes()
+		virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault)).AnyTimes()
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault)).AnyTimes()
+
+		collector = runner.NewRunner(k8sv1.NamespaceDefault, virtClient, time.Minute)
+	})
+
+	vmiExists := func(name string) bool {
+		_, err := virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).Get(
+			context.TODO(), name, metav1.GetOptions{})
+
+		return !k8serrors.IsNotFound(err)
+	}
+
+	It("deletes the resources whose runner pod is gone", func() {
+		results, err := collector.CollectGarbage(context.TODO(), runner.GCOptions{})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+		Expect(results).To(ContainElements(
+			HaveField("Name", orphanedVMI), HaveField("Name", orphanedDV),
+		))
+		Expect(results).To(HaveEach(HaveField("Deleted", BeTrue())))
+		Expect(vmiExists(orphanedVMI)).To(BeFalse())
+		Expect(vmiExists(liveVMI)).To(BeTrue())
+		Expect(vmiExists("unmanaged")).To(BeTrue())
+
+		_, err = cdiClientset.CdiV1beta1().DataVolumes(k8sv1.NamespaceDefault).Get(
+			context.TODO(), orphanedDV, metav1.GetOptions{})
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("deletes the resources older than the max age", func() {
+		results, err := collector.CollectGarbage(context.TODO(), runner.GCOptions{MaxAge: 90 * time.Minute})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(ContainElement(And(
+			HaveField("Name", liveVMI), HaveField("Reason", ContainSubstring("older than")),
+		)))
+		Expect(vmiExists(liveVMI)).To(BeFalse())
+		Expect(vmiExists("unmanaged")).To(BeTrue())
+	})
+
+	It("ages the pool runners from their claim", func() {
+		warm := NewVirtualMachineInstance("runner-warm")
+		managed(&warm.ObjectMeta, livePod, 2*time.Hour)
+		warm.Labels[runner.PoolStateLabel] = runner.PoolStateWarm
+
+		claimed := NewVirtualMachineInstance("runner-claimed")
+		managed(&claimed.ObjectMeta, livePod, 2*time.Hour)
+		claimed.Labels[runner.PoolStateLabel] = runner.PoolStateClaimed
+		claimed.Annotations[runner.ClaimedAtAnnotation] = time.Now().Add(-time.Minute).Format(time.RFC3339)
+
+		Expect(virtClientset.Tracker().Add(warm)).To(Succeed())
+		Expect(virtClientset.Tracker().Add(claimed)).To(Succeed())
+
+		results, err := collector.CollectGarbage(context.TODO(), runner.GCOptions{MaxAge: 90 * time.Minute})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(ContainElement(HaveField("Name", liveVMI)))
+		Expect(results).NotTo(ContainElement(HaveField("Name", "runner-warm")))
+		Expect(results).NotTo(ContainElement(HaveField("Name", "runner-claimed")))
+		Expect(vmiExists("runner-warm")).To(BeTrue())
+		Expect(vmiExists("runner-claimed")).To(BeTrue())
+	})
+
+	It("honours the preservation of failed runners", func() {
+		preserved := NewVirtualMachineInstance("runner-preserved")
+		managed(&preserved.ObjectMeta, "gone-pod", time.Hour)
+		preserved.Annotations[runner.PreserveUntilAnnotation] = time.Now().Add(time.Hour).Format(time.RFC3339)
+
+		expired := NewVirtualMachineInstance("runner-expired")
+		managed(&expired.ObjectMeta, livePod, time.Hour)
+		expired.Annotations[runner.PreserveUntilAnnotation] = time.Now().Add(-time.Minute).Format(time.RFC3339)
+
+		Expect(virtClientset.Tracker().Add(preserved)).To(Succeed())
+		Expect(virtClientset.Tracker().Add(expired)).To(Succeed())
+
+		results, err := collector.CollectGarbage(context.TODO(), runner.GCOptions{})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(ContainElement(And(
+			HaveField("Name", "runner-expired"), HaveField("Reason", ContainSubstring("preserved until")),
+		)))
+		Expect(results).NotTo(ContainElement(HaveField("Name", "runner-preserved")))
+		Expect(vmiExists("runner-preserved")).To(BeTrue())
+	})
+
+	It("keeps the data volumes of a preserved virtual machine", func() {
+		preserved := NewVirtualMachine("runner-preserved")
+		preserved.UID = "preserved-vm-uid"
+		managed(&preserved.ObjectMeta, "gone-pod", time.Hour)
+		preserved.Annotations[runner.PreserveUntilAnnotation] = time.Now().Add(time.Hour).Format(time.RFC3339)
+
+		disk := NewDataVolume("disk-runner-preserved")
+		managed(&disk.ObjectMeta, "gone-pod", time.Hour)
+		disk.OwnerReferences = []metav1.OwnerReference{
+			{Kind: v1.VirtualMachineGroupVersionKind.Kind, Name: preserved.Name, UID: preserved.UID},
+		}
+
+		Expect(virtClientset.Tracker().Add(preserved)).To(Succeed())
+		Expect(cdiClientset.Tracker().Add(disk)).To(Succeed())
+
+		results, err := collector.CollectGarbage(context.TODO(), runner.GCOptions{MaxAge: 30 * time.Minute})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).NotTo(ContainElement(HaveField("Name", disk.Name)))
+
+		_, err = cdiClientset.CdiV1beta1().DataVolumes(k8sv1.NamespaceDefault).Get(
+			context.TODO(), disk.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("only reports the orphaned resources in dry run mode", func() {
+		results, err := collector.CollectGarbage(context.TODO(), runner.GCOptions{DryRun: true})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+		Expect(results).To(HaveEach(HaveField("Deleted", BeFalse())))
+		Expect(vmiExists(orphanedVMI)).To(BeTrue())
+	})
+
+	It("reports the resources that couldn't be deleted", func() {
+		virtClientset.PrependReactor("delete", "virtualmachineinstances",
+			func(_ k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, k8serrors.NewForbidden(v1.Resource("virtualmachineinstances"), orphanedVMI, nil)
+			})
+
+		results, err := collector.CollectGarbage(context.TODO(), runner.GCOptions{})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(ContainElement(And(
+			HaveField("Name", orphanedVMI), HaveField("Deleted", BeFalse()), HaveField("Error", Not(BeEmpty())),
+		)))
+	})
+
+	It("fails when the runner pod can't be checked", func() {
+		k8sClientset.PrependReactor("get", "pods", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, k8serrors.NewForbidden(k8sv1.Resource("pods"), livePod, nil)
+		})
+
+		_, err := collector.CollectGarbage(context.TODO(), runner.GCOptions{})
+
+		Expect(k8serrors.IsForbidden(err)).To(BeTrue())
+	})
+})
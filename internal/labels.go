@@ -0,0 +1,170 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// ManagedByLabel marks the resources created by kar.
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// ManagedByValue is the value of ManagedByLabel on the resources created by kar.
+	ManagedByValue = "kubevirt-actions-runner"
+	// RunnerNameLabel records the name of the runner a resource belongs to.
+	RunnerNameLabel = "electrocucaracha.kubevirt-actions-runner/runner-name"
+	// TemplateLabel records the name of the template a resource was created from.
+	TemplateLabel = "electrocucaracha.kubevirt-actions-runner/template"
+	// TemplateNamespaceLabel records the namespace of the template a resource was created from.
+	TemplateNamespaceLabel = "electrocucaracha.kubevirt-actions-runner/template-namespace"
+	// GitHubRepositoryLabel records the GitHub repository of the job, with "/" replaced by "_".
+	GitHubRepositoryLabel = "electrocucaracha.kubevirt-actions-runner/github-repository"
+	// GitHubWorkflowLabel records the GitHub workflow of the job.
+	GitHubWorkflowLabel = "electrocucaracha.kubevirt-actions-runner/github-workflow"
+	// GitHubRunIDLabel records the GitHub workflow run ID of the job.
+	GitHubRunIDLabel = "electrocucaracha.kubevirt-actions-runner/github-run-id"
+	// RunnerPodAnnotation records the <namespace>/<name> of the pod that
+	// created a resource.
+	RunnerPodAnnotation = "electrocucaracha.kubevirt-actions-runner/runner-pod"
+)
+
+// invalidLabelValueChars matches the characters that aren't allowed in a
+// label value.
+var invalidLabelValueChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// ResourceMetadata describes the labels and annotations added to every
+// resource created by the runner, on top of the management labels.
+type ResourceMetadata struct {
+	// GitHubRepository is the owner/name of the repository running the job.
+	GitHubRepository string
+	// GitHubWorkflow is the name of the workflow running the job.
+	GitHubWorkflow string
+	// GitHubRunID is the ID of the workflow run.
+	GitHubRunID string
+	// Labels are extra labels; they can't replace the management labels.
+	Labels map[string]string
+	// Annotations are extra annotations; they can't replace the runner annotations.
+	Annotations map[string]string
+}
+
+// validate checks that the extra labels and annotations are accepted by the
+// Kubernetes API.
+func (m ResourceMetadata) validate() error {
+	for _, key := range slices.Sorted(maps.Keys(m.Labels)) {
+		errs := append(validation.IsQualifiedName(key), validation.IsValidLabelValue(m.Labels[key])...)
+		if len(errs) > 0 {
+			return fmt.Errorf("%w: label %s=%s: %s", ErrInvalidResourceMetadata, key, m.Labels[key],
+				strings.Join(errs, ", "))
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(m.Annotations)) {
+		errs := validation.IsQualifiedName(key)
+		if len(errs) > 0 {
+			return fmt.Errorf("%w: annotation %s: %s", ErrInvalidResourceMetadata, key, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
+
+// managementMetadata holds the labels and annotations stamped on the
+// resources of a single runner.
+type managementMetadata struct {
+	labels      map[string]string
+	annotations map[string]string
+}
+
+// managementMetadata builds the labels and annotations that identify the
+// resources created for a runner.
+func (rc *KubevirtRunner) managementMetadata(
+	vmTemplate, vmTemplateNamespace, runnerName string,
+	metadata ResourceMetadata,
+) managementMetadata {
+	labels := maps.Clone(metadata.Labels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	maps.Copy(labels, map[string]string{
+		ManagedByLabel:         ManagedByValue,
+		RunnerNameLabel:        labelValue(runnerName),
+		TemplateLabel:          labelValue(vmTemplate),
+		TemplateNamespaceLabel: labelValue(vmTemplateNamespace),
+	})
+
+	for key, value := range map[string]string{
+		GitHubRepositoryLabel: metadata.GitHubRepository,
+		GitHubWorkflowLabel:   metadata.GitHubWorkflow,
+		GitHubRunIDLabel:      metadata.GitHubRunID,
+	} {
+		if value = labelValue(value); value != "" {
+			labels[key] = value
+		}
+	}
+
+	annotations := maps.Clone(metadata.Annotations)
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	if rc.runnerPod != nil {
+		annotations[RunnerPodAnnotation] = rc.runnerPod.Namespace + "/" + rc.runnerPod.Name
+	}
+
+	return managementMetadata{labels: labels, annotations: annotations}
+}
+
+// apply adds the labels and annotations to a resource, replacing the ones
+// inherited from the template with the same key.
+func (m managementMetadata) apply(meta *k8smetav1.ObjectMeta) {
+	if meta.Labels == nil {
+		meta.Labels = map[string]string{}
+	}
+
+	maps.Copy(meta.Labels, m.labels)
+
+	if len(m.annotations) == 0 {
+		return
+	}
+
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+
+	maps.Copy(meta.Annotations, m.annotations)
+}
+
+// labelValue turns an arbitrary string into a valid label value by replacing
+// the invalid characters with "_" and trimming it to the maximum length.
+func labelValue(value string) string {
+	value = invalidLabelValueChars.ReplaceAllString(value, "_")
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+
+	return strings.Trim(value, "._-")
+}
@@ -0,0 +1,383 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	v1 "kubevirt.io/api/core/v1"
+)
+
+const (
+	// ociTemplateMaxSize bounds the size of the manifests and of the template
+	// layer read from a registry.
+	ociTemplateMaxSize = 1 << 20
+
+	ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	ociTitleAnnotation   = "org.opencontainers.image.title"
+	ociPathComponent     = `[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*`
+)
+
+// ociRepositoryPattern, ociTagPattern and ociDigestPattern follow the
+// grammar of the OCI distribution specification.
+var (
+	ociRepositoryPattern = regexp.MustCompile(`^` + ociPathComponent + `(?:/` + ociPathComponent + `)*$`)
+	ociTagPattern        = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	ociDigestPattern     = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+// bearerChallengeParam matches the parameters of a WWW-Authenticate Bearer
+// challenge, such as realm="https://auth.example.com/token".
+var bearerChallengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// WithRegistryClient sends the requests of the oci template source through
+// client instead of http.DefaultClient.
+func WithRegistryClient(client *http.Client) Option {
+	return func(rc *KubevirtRunner) {
+		rc.registryClient = client
+	}
+}
+
+// ociReference locates an OCI artifact: <registry>/<repository>, followed by
+// :<tag> or @<digest>.
+type ociReference struct {
+	registry   string
+	repository string
+	reference  string
+}
+
+// parseOCIReference parses a template name of the oci source. The registry
+// host is required and the tag defaults to latest.
+func parseOCIReference(name string) (ociReference, error) {
+	registry, repository, found := strings.Cut(name, "/")
+	if !found || registry == "" || repository == "" {
+		return ociReference{}, fmt.Errorf("%w: oci template %q isn't of the form <registry>/<repository>:<tag>",
+			ErrInvalidTemplateSource, name)
+	}
+
+	ref := ociReference{registry: registry, repository: repository, reference: "latest"}
+
+	if repository, digest, found := strings.Cut(ref.repository, "@"); found {
+		ref.repository, ref.reference = repository, digest
+	} else if slash := strings.LastIndex(ref.repository, "/"); strings.Contains(ref.repository[slash+1:], ":") {
+		colon := strings.LastIndex(ref.repository, ":")
+		ref.repository, ref.reference = ref.repository[:colon], ref.repository[colon+1:]
+	}
+
+	if !ociRepositoryPattern.MatchString(ref.repository) ||
+		!ociTagPattern.MatchString(ref.reference) && !ociDigestPattern.MatchString(ref.reference) {
+		return ociReference{}, fmt.Errorf("%w: oci template %q has an invalid repository, tag or digest",
+			ErrInvalidTemplateSource, name)
+	}
+
+	return ref, nil
+}
+
+func (r ociReference) url(kind, reference string) string {
+	return "https://" + r.registry + "/v2/" + r.repository + "/" + kind + "/" + reference
+}
+
+// ociDescriptor and ociManifest hold the fields of an OCI image manifest
+// needed to find the template layer.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// templateLayer returns the only layer of the artifact, or the first one
+// whose title or media type is YAML or JSON.
+func (m ociManifest) templateLayer() (ociDescriptor, error) {
+	if len(m.Layers) == 1 {
+		return m.Layers[0], nil
+	}
+
+	for _, layer := range m.Layers {
+		switch path.Ext(layer.Annotations[ociTitleAnnotation]) {
+		case ".yaml", ".yml", ".json":
+			return layer, nil
+		}
+
+		if strings.HasSuffix(layer.MediaType, "yaml") || strings.HasSuffix(layer.MediaType, "json") {
+			return layer, nil
+		}
+	}
+
+	return ociDescriptor{}, fmt.Errorf("%w: oci artifact has no yaml or json layer", ErrInvalidTemplateSource)
+}
+
+// registryError is an unexpected status returned by an OCI registry.
+type registryError struct {
+	url    string
+	status int
+}
+
+func (e *registryError) Error() string {
+	return fmt.Sprintf("registry returned %d %s for %s", e.status, http.StatusText(e.status), e.url)
+}
+
+// isTransientRegistryError reports whether the registry may answer the
+// request when it is retried.
+func isTransientRegistryError(err error) bool {
+	var registryErr *registryError
+
+	return errors.As(err, &registryErr) &&
+		(registryErr.status == http.StatusTooManyRequests || registryErr.status >= http.StatusInternalServerError)
+}
+
+type ociTemplateSource struct {
+	rc *KubevirtRunner
+}
+
+func (s ociTemplateSource) LoadTemplate(ctx context.Context, name, _ string) (*v1.VirtualMachine, error) {
+	ref, err := parseOCIReference(name)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &ociRegistry{client: s.rc.registryClient}
+	if registry.client == nil {
+		registry.client = http.DefaultClient
+	}
+
+	data, err := withRetry(ctx, s.rc.retryPolicy, "get template oci manifest", func() ([]byte, error) {
+		return registry.get(ctx, ref, ref.url("manifests", ref.reference), ociManifestMediaType)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the manifest of oci template %q: %w", name, err)
+	}
+
+	var manifest ociManifest
+
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: oci template %q has a malformed manifest: %w", ErrInvalidTemplateSource, name, err)
+	}
+
+	if manifest.MediaType != "" && manifest.MediaType != ociManifestMediaType {
+		return nil, fmt.Errorf("%w: oci template %q is a %s, not an image manifest",
+			ErrInvalidTemplateSource, name, manifest.MediaType)
+	}
+
+	layer, err := manifest.templateLayer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the layer of oci template %q: %w", name, err)
+	}
+
+	data, err = s.getLayer(ctx, registry, ref, layer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the layer of oci template %q: %w", name, err)
+	}
+
+	virtualMachine, err := decodeTemplate(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode oci template %q: %w", name, err)
+	}
+
+	return virtualMachine, nil
+}
+
+// getLayer downloads the template layer and checks it against its digest.
+func (s ociTemplateSource) getLayer(
+	ctx context.Context,
+	registry *ociRegistry,
+	ref ociReference,
+	layer ociDescriptor,
+) ([]byte, error) {
+	if !ociDigestPattern.MatchString(layer.Digest) {
+		return nil, fmt.Errorf("%w: unsupported layer digest %q", ErrInvalidTemplateSource, layer.Digest)
+	}
+
+	if layer.Size > ociTemplateMaxSize {
+		return nil, fmt.Errorf("%w: layer of %d bytes exceeds %d bytes",
+			ErrInvalidTemplateSource, layer.Size, ociTemplateMaxSize)
+	}
+
+	data, err := withRetry(ctx, s.rc.retryPolicy, "get template oci layer", func() ([]byte, error) {
+		return registry.get(ctx, ref, ref.url("blobs", layer.Digest), "")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	if "sha256:"+hex.EncodeToString(sum[:]) != layer.Digest {
+		return nil, fmt.Errorf("%w: layer doesn't match its digest %s", ErrInvalidTemplateSource, layer.Digest)
+	}
+
+	return data, nil
+}
+
+// ociRegistry reads from an OCI registry, following the anonymous Bearer
+// token flow when the registry asks for one.
+type ociRegistry struct {
+	client *http.Client
+	token  string
+}
+
+func (c *ociRegistry) get(ctx context.Context, ref ociReference, target, accept string) ([]byte, error) {
+	resp, err := c.do(ctx, target, accept)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && c.token == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		_ = resp.Body.Close()
+
+		c.token, err = c.fetchToken(ctx, ref, challenge)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err = c.do(ctx, target, accept)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %w", ErrTemplateNotFound, &registryError{url: target, status: resp.StatusCode})
+	case resp.StatusCode != http.StatusOK:
+		return nil, &registryError{url: target, status: resp.StatusCode}
+	}
+
+	return readLimited(resp.Body)
+}
+
+func (c *ociRegistry) do(ctx context.Context, target, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTemplateSource, err)
+	}
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach the registry: %w", err)
+	}
+
+	return resp, nil
+}
+
+// fetchToken requests an anonymous pull token from the realm of a Bearer
+// challenge.
+func (c *ociRegistry) fetchToken(ctx context.Context, ref ociReference, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("%w: registry requires %q authentication", ErrInvalidTemplateSource, scheme)
+	}
+
+	values := map[string]string{}
+	for _, match := range bearerChallengeParam.FindAllStringSubmatch(params, -1) {
+		values[match[1]] = match[2]
+	}
+
+	realm, err := url.Parse(values["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("%w: registry challenge has an invalid realm %q",
+			ErrInvalidTemplateSource, values["realm"])
+	}
+
+	query := realm.Query()
+	if service := values["service"]; service != "" {
+		query.Set("service", service)
+	}
+
+	scope := values["scope"]
+	if scope == "" {
+		scope = "repository:" + ref.repository + ":pull"
+	}
+
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	resp, err := c.do(ctx, realm.String(), "")
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &registryError{url: realm.String(), status: resp.StatusCode}
+	}
+
+	data, err := readLimited(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+
+	err = json.Unmarshal(data, &token)
+	if err != nil || token.Token+token.AccessToken == "" {
+		return "", fmt.Errorf("%w: registry returned no token", ErrInvalidTemplateSource)
+	}
+
+	if token.Token != "" {
+		return token.Token, nil
+	}
+
+	return token.AccessToken, nil
+}
+
+// readLimited reads a registry response of at most ociTemplateMaxSize bytes.
+func readLimited(body io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, ociTemplateMaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the registry response: %w", err)
+	}
+
+	if len(data) > ociTemplateMaxSize {
+		return nil, fmt.Errorf("%w: registry response exceeds %d bytes", ErrInvalidTemplateSource, ociTemplateMaxSize)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,213 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	k8sv1 "k8s.io/api/core/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	cdifake "kubevirt.io/client-go/containerizeddataimporter/fake"
+	"kubevirt.io/client-go/kubecli"
+)
+
+var _ = Describe("OCI template source", func() {
+	const (
+		runnerName = "runner-oci"
+		repository = "runners/windows"
+		pullToken  = "pull-token"
+		manifest   = `apiVersion: kubevirt.io/v1
+kind: VirtualMachine
+metadata:
+  name: windows-2022
+spec:
+  template:
+    spec:
+      domain:
+        cpu:
+          cores: 8
+`
+	)
+
+	var (
+		renderer          runner.Renderer
+		registry          *httptest.Server
+		layer             []byte
+		manifestFailures  int
+		manifestRequests  int
+		authorizedRequest bool
+	)
+
+	digest := func(data []byte) string {
+		sum := sha256.Sum256(data)
+
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	serveRegistry := func(w http.ResponseWriter, r *http.Request) {
+		host := registry.Listener.Addr().String()
+
+		if r.URL.Path == "/token" {
+			if r.URL.Query().Get("scope") != "repository:"+repository+":pull" {
+				w.WriteHeader(http.StatusForbidden)
+
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": pullToken})
+
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer "+pullToken {
+			w.Header().Set("WWW-Authenticate",
+				`Bearer realm="https://`+host+`/token",service="`+host+`",scope="repository:`+repository+`:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		authorizedRequest = true
+
+		switch r.URL.Path {
+		case "/v2/" + repository + "/manifests/2022":
+			manifestRequests++
+			if manifestRequests <= manifestFailures {
+				w.WriteHeader(http.StatusServiceUnavailable)
+
+				return
+			}
+
+			readme := []byte("# Windows runner\n")
+
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"schemaVersion": 2,
+				"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+				"layers": []map[string]any{
+					{
+						"mediaType":   "text/markdown",
+						"digest":      digest(readme),
+						"size":        len(readme),
+						"annotations": map[string]string{"org.opencontainers.image.title": "README.md"},
+					},
+					{
+						"mediaType":   "application/vnd.oci.image.layer.v1.tar",
+						"digest":      digest([]byte(manifest)),
+						"size":        len(manifest),
+						"annotations": map[string]string{"org.opencontainers.image.title": "windows-2022.yaml"},
+					},
+				},
+			})
+		case "/v2/" + repository + "/blobs/" + digest([]byte(manifest)):
+			_, _ = w.Write(layer)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	render := func(template string) (*runner.RenderedResources, error) {
+		return renderer.RenderResources(context.TODO(), runner.RenderOptions{
+			VMTemplate: template,
+			RunnerName: runnerName,
+			Template:   runner.TemplateOptions{Source: runner.TemplateSourceOCI},
+		})
+	}
+
+	reference := func(tag string) string {
+		return registry.Listener.Addr().String() + "/" + repository + ":" + tag
+	}
+
+	BeforeEach(func() {
+		layer = []byte(manifest)
+		manifestFailures = 0
+		manifestRequests = 0
+		authorizedRequest = false
+
+		registry = httptest.NewTLSServer(http.HandlerFunc(serveRegistry))
+		DeferCleanup(registry.Close)
+
+		mockCtrl := gomock.NewController(GinkgoT())
+		virtClient := kubecli.NewMockKubevirtClient(mockCtrl)
+
+		virtClient.EXPECT().CdiClient().Return(cdifake.NewSimpleClientset()).AnyTimes()
+		virtClient.EXPECT().CoreV1().Return(k8sfake.NewSimpleClientset().CoreV1()).AnyTimes()
+
+		renderer = runner.NewRunner(k8sv1.NamespaceDefault, virtClient, time.Minute,
+			runner.WithRegistryClient(registry.Client()),
+			runner.WithRetryPolicy(runner.RetryPolicy{
+				MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond,
+			}))
+	})
+
+	It("loads the template from the yaml layer of the artifact", func() {
+		rendered, err := render(reference("2022"))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(authorizedRequest).To(BeTrue())
+		Expect(rendered.VirtualMachineInstance.Name).To(Equal(runnerName))
+		Expect(rendered.VirtualMachineInstance.Spec.Domain.CPU.Cores).To(Equal(uint32(8)))
+	})
+
+	It("retries the manifest request after transient registry errors", func() {
+		manifestFailures = 1
+
+		_, err := render(reference("2022"))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manifestRequests).To(Equal(2))
+	})
+
+	It("fails when the tag doesn't exist", func() {
+		_, err := render(reference("2019"))
+
+		Expect(err).To(MatchError(runner.ErrTemplateNotFound))
+	})
+
+	It("fails when the layer doesn't match its digest", func() {
+		layer = []byte(strings.Replace(manifest, "cores: 8", "cores: 9", 1))
+
+		_, err := render(reference("2022"))
+
+		Expect(err).To(MatchError(runner.ErrInvalidTemplateSource))
+		Expect(err).To(MatchError(ContainSubstring("doesn't match its digest")))
+	})
+
+	DescribeTable("rejects malformed references", func(template string) {
+		_, err := render(template)
+
+		Expect(err).To(MatchError(runner.ErrInvalidTemplateSource))
+	},
+		Entry("when the registry is missing", "windows:2022"),
+		Entry("when the repository has upper case letters", "registry.example.com/Runners/windows:2022"),
+		Entry("when the tag is empty", "registry.example.com/runners/windows:"),
+		Entry("when the digest is malformed", "registry.example.com/runners/windows@sha256:1234"),
+	)
+})
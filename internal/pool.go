@@ -0,0 +1,604 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/validation"
+	v1 "kubevirt.io/api/core/v1"
+)
+
+const (
+	// PoolLabel records the name of the warm pool a runner belongs to.
+	PoolLabel = "electrocucaracha.kubevirt-actions-runner/pool"
+	// PoolStateLabel records whether a pooled runner is waiting for a job or
+	// has been claimed by one.
+	PoolStateLabel = "electrocucaracha.kubevirt-actions-runner/pool-state"
+	// PoolStateWarm is the PoolStateLabel value of the runners waiting for a job.
+	PoolStateWarm = "warm"
+	// PoolStateClaimed is the PoolStateLabel value of the runners adopted by a job.
+	PoolStateClaimed = "claimed"
+	// ClaimedAtAnnotation records when a job claimed a pooled runner, in RFC 3339.
+	ClaimedAtAnnotation = "electrocucaracha.kubevirt-actions-runner/claimed-at"
+	// PoolSpecAnnotation records the resource overrides and the disk restore
+	// strategy a pooled runner was created with, so only the jobs requesting
+	// the same ones claim it.
+	PoolSpecAnnotation = "electrocucaracha.kubevirt-actions-runner/pool-spec"
+
+	poolNameSuffixLength = 5
+)
+
+// PoolSpec describes the warm runners a pool keeps booted.
+type PoolSpec struct {
+	// Name identifies the pool; it must be a valid label value and DNS label.
+	Name string
+	// Template and TemplateNamespace locate the VirtualMachine template.
+	Template          string
+	TemplateNamespace string
+	// Size is the number of warm runners to keep.
+	Size int
+	// TemplateOptions selects how the template is loaded and its disks created.
+	TemplateOptions TemplateOptions
+	// Overrides are applied to every runner of the pool.
+	Overrides ResourceOverrides
+	// Metadata is added to every runner of the pool.
+	Metadata ResourceMetadata
+}
+
+// PoolStatus reports the outcome of a pool reconciliation.
+type PoolStatus struct {
+	// Warm is the number of runners waiting for a job, booted or not.
+	Warm int
+	// Ready is the number of warm runners that are Running and Ready.
+	Ready int
+	// Claimed is the number of runners adopted by a job.
+	Claimed int
+	// Created is the number of runners created by the reconciliation.
+	Created int
+	// Deleted is the number of runners deleted by the reconciliation.
+	Deleted int
+}
+
+// Pooler keeps a pool of pre-booted runners and hands them over to jobs.
+type Pooler interface {
+	// ReconcilePool replaces the finished runners of the pool and creates or
+	// deletes warm runners until spec.Size of them exist.
+	ReconcilePool(ctx context.Context, spec PoolSpec) (PoolStatus, error)
+	// ClaimRunner adopts a Running and Ready runner of the pool created with
+	// the same template options and overrides for a job and injects its JIT
+	// config. It returns ErrNoWarmRunner when none is available.
+	ClaimRunner(ctx context.Context,
+		pool, vmTemplate, vmTemplateNamespace, runnerName, jitConfig string,
+		template TemplateOptions, overrides ResourceOverrides, metadata ResourceMetadata,
+	) error
+}
+
+var _ Pooler = (*KubevirtRunner)(nil)
+
+// ReconcilePool replaces the finished runners of the pool and creates or
+// deletes warm runners until spec.Size of them exist.
+func (rc *KubevirtRunner) ReconcilePool(ctx context.Context, spec PoolSpec) (PoolStatus, error) {
+	tracer := otel.Tracer(tracerName)
+
+	ctx, span := tracer.Start(ctx, "ReconcilePool",
+		trace.WithAttributes(
+			attribute.String("pool", spec.Name),
+			attribute.Int("size", spec.Size),
+		),
+	)
+	defer span.End()
+
+	var status PoolStatus
+
+	err := rc.validatePoolSpec(spec)
+	if err != nil {
+		span.RecordError(err)
+
+		return status, err
+	}
+
+	vmis, err := rc.virtClient.VirtualMachineInstance(rc.namespace).List(ctx, k8smetav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{
+			ManagedByLabel: ManagedByValue,
+			PoolLabel:      spec.Name,
+		}).String(),
+	})
+	if err != nil {
+		span.RecordError(err)
+
+		return status, fmt.Errorf("cannot list the runners of the %s pool: %w", spec.Name, err)
+	}
+
+	warm := make([]v1.VirtualMachineInstance, 0, len(vmis.Items))
+
+	for _, vmi := range vmis.Items {
+		switch {
+		case vmi.DeletionTimestamp != nil:
+		case vmi.Labels[PoolStateLabel] == PoolStateClaimed:
+			// The job that claimed the runner deletes it.
+			status.Claimed++
+		case vmi.IsFinal():
+			// A warm runner that stopped can't take a job anymore.
+			err = rc.deletePoolRunner(ctx, span, vmi.Name, &status)
+			if err != nil {
+				return status, err
+			}
+		default:
+			warm = append(warm, vmi)
+		}
+	}
+
+	// The oldest runners are the most likely to be booted, so the newest ones
+	// go first when the pool shrinks.
+	slices.SortFunc(warm, func(a, b v1.VirtualMachineInstance) int {
+		return a.CreationTimestamp.Compare(b.CreationTimestamp.Time)
+	})
+
+	for len(warm) > spec.Size {
+		err = rc.deletePoolRunner(ctx, span, warm[len(warm)-1].Name, &status)
+		if err != nil {
+			return status, err
+		}
+
+		warm = warm[:len(warm)-1]
+	}
+
+	for _, vmi := range warm {
+		if vmi.Status.Phase == v1.Running && isVMIReady(&vmi) {
+			status.Ready++
+		}
+	}
+
+	status.Warm = len(warm)
+
+	for status.Warm < spec.Size {
+		err = rc.createPoolRunner(ctx, tracer, span, spec)
+		if err != nil {
+			return status, err
+		}
+
+		status.Warm++
+		status.Created++
+	}
+
+	span.SetAttributes(
+		attribute.Int("warm", status.Warm),
+		attribute.Int("ready", status.Ready),
+		attribute.Int("claimed", status.Claimed),
+	)
+
+	return status, nil
+}
+
+func (rc *KubevirtRunner) validatePoolSpec(spec PoolSpec) error {
+	if rc.virtualMachine {
+		return ErrPoolUnsupported
+	}
+
+	if errs := validation.IsDNS1123Label(spec.Name); len(errs) > 0 {
+		return fmt.Errorf("%w: name %q: %v", ErrInvalidPoolSpec, spec.Name, errs)
+	}
+
+	if spec.Size < 0 {
+		return fmt.Errorf("%w: negative size %d", ErrInvalidPoolSpec, spec.Size)
+	}
+
+	err := rc.resourceLimits.validate(spec.Overrides)
+	if err != nil {
+		return err
+	}
+
+	return spec.Metadata.validate()
+}
+
+// createPoolRunner creates a warm runner whose runner-info Secret has no JIT
+// config yet. The Secret is shared through virtiofs, so the guest sees the
+// JIT config injected by ClaimRunner without a reboot.
+func (rc *KubevirtRunner) createPoolRunner(
+	ctx context.Context,
+	tracer trace.Tracer,
+	span trace.Span,
+	spec PoolSpec,
+) error {
+	templateNamespace := spec.TemplateNamespace
+	if templateNamespace == "" {
+		templateNamespace = k8smetav1.NamespaceDefault
+	}
+
+	runnerName := spec.Name + "-" + utilrand.String(poolNameSuffixLength)
+
+	management := rc.managementMetadata(spec.Template, templateNamespace, runnerName, spec.Metadata)
+	management.labels[PoolLabel] = spec.Name
+	management.labels[PoolStateLabel] = PoolStateWarm
+	management.annotations[PoolSpecAnnotation] = poolRunnerSpec(spec.TemplateOptions, spec.Overrides)
+
+	virtualMachineInstance, dataVolumes, secret, err := rc.getResources(
+		ctx, spec.Template, templateNamespace, runnerName, "", spec.TemplateOptions, spec.Overrides, management)
+	if err != nil {
+		span.RecordError(err)
+
+		return err
+	}
+
+	shareRunnerInfo(&virtualMachineInstance.Spec)
+
+	_, err = rc.createInstanceResources(ctx, tracer, span, virtualMachineInstance, dataVolumes, secret,
+		time.Now().Add(rc.waitTimeout))
+	if err != nil {
+		return err
+	}
+
+	utils.GetLogger().Printf("Created %s warm runner in the %s pool\n", runnerName, spec.Name)
+
+	return nil
+}
+
+// shareRunnerInfo exposes the runner-info volume through virtiofs instead of
+// a disk, so updates of the Secret reach the running guest.
+func shareRunnerInfo(spec *v1.VirtualMachineInstanceSpec) {
+	for _, filesystem := range spec.Domain.Devices.Filesystems {
+		if filesystem.Name == runnerInfoVolume {
+			return
+		}
+	}
+
+	spec.Domain.Devices.Filesystems = append(spec.Domain.Devices.Filesystems, v1.Filesystem{
+		Name:     runnerInfoVolume,
+		Virtiofs: &v1.FilesystemVirtiofs{},
+	})
+}
+
+func (rc *KubevirtRunner) deletePoolRunner(
+	ctx context.Context,
+	span trace.Span,
+	name string,
+	status *PoolStatus,
+) error {
+	err := rc.deleteVMI(ctx, span, name, k8smetav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("cannot delete pool runner %s: %w", name, err)
+	}
+
+	status.Deleted++
+
+	return nil
+}
+
+// ClaimRunner adopts the oldest Running and Ready runner of the pool that was
+// created from the same template, with the same overrides and disk restore
+// strategy. The claim is a merge patch conditioned on
+// the resource version of the runner, so concurrent jobs never adopt the same
+// one. The runner then takes the labels, annotations and owner of the job,
+// and its runner-info Secret receives the JIT config.
+func (rc *KubevirtRunner) ClaimRunner(ctx context.Context,
+	pool, vmTemplate, vmTemplateNamespace, runnerName, jitConfig string,
+	template TemplateOptions, overrides ResourceOverrides, metadata ResourceMetadata,
+) error {
+	tracer := otel.Tracer(tracerName)
+
+	if vmTemplateNamespace == "" {
+		vmTemplateNamespace = k8smetav1.NamespaceDefault
+	}
+
+	ctx, span := tracer.Start(ctx, "ClaimRunner",
+		trace.WithAttributes(
+			attribute.String("pool", pool),
+			attribute.String("runnerName", runnerName),
+		),
+	)
+	defer span.End()
+
+	if rc.virtualMachine {
+		return fmt.Errorf("%w: %w", ErrNoWarmRunner, ErrPoolUnsupported)
+	}
+
+	err := rc.validateResourceInputs(vmTemplate, runnerName, jitConfig, span)
+	if err != nil {
+		return err
+	}
+
+	err = metadata.validate()
+	if err != nil {
+		span.RecordError(err)
+
+		return err
+	}
+
+	candidates, err := rc.warmRunners(ctx, pool, vmTemplate, vmTemplateNamespace)
+	if err != nil {
+		span.RecordError(err)
+
+		return fmt.Errorf("%w: %w", ErrNoWarmRunner, err)
+	}
+
+	runnerSpec := poolRunnerSpec(template, overrides)
+	warm := len(candidates)
+
+	candidates = slices.DeleteFunc(candidates, func(vmi v1.VirtualMachineInstance) bool {
+		return warmRunnerSpec(&vmi) != runnerSpec
+	})
+
+	if warm > 0 && len(candidates) == 0 {
+		return fmt.Errorf("%w: the warm runners weren't created with %s", ErrNoWarmRunner, runnerSpec)
+	}
+
+	management := rc.managementMetadata(vmTemplate, vmTemplateNamespace, runnerName, metadata)
+	management.labels[PoolLabel] = labelValue(pool)
+	management.labels[PoolStateLabel] = PoolStateClaimed
+	metadataPatch := claimMetadata(management)
+
+	// A runner that can't be claimed or adopted is skipped, so the job falls
+	// back to a new runner rather than failing when none of them works.
+	var claimErrs []error
+
+	for _, candidate := range candidates {
+		claimed, err := rc.claimPoolRunner(ctx, &candidate, metadataPatch)
+		if err != nil {
+			span.RecordError(err)
+			claimErrs = append(claimErrs, err)
+
+			continue
+		}
+
+		if !claimed {
+			continue
+		}
+
+		span.SetAttributes(attribute.String("vmiName", candidate.Name))
+
+		err = rc.adoptPoolRunner(ctx, &candidate, metadataPatch, jitConfig)
+		if err != nil {
+			span.RecordError(err)
+			utils.GetLogger().Printf("Dropping warm runner %s: %v\n", candidate.Name, err)
+
+			// The runner would never pick up a job, so it is dropped from the pool.
+			deleteErr := rc.deleteVMI(ctx, span, candidate.Name, k8smetav1.DeleteOptions{})
+			if deleteErr != nil && !k8serrors.IsNotFound(deleteErr) {
+				err = errors.Join(err, deleteErr)
+			}
+
+			claimErrs = append(claimErrs, err)
+
+			continue
+		}
+
+		return nil
+	}
+
+	if len(claimErrs) > 0 {
+		return fmt.Errorf("%w: %w", ErrNoWarmRunner, errors.Join(claimErrs...))
+	}
+
+	return ErrNoWarmRunner
+}
+
+// poolRunnerSpec returns the PoolSpecAnnotation value of the runners created
+// with the template options and overrides. The template source isn't part of
+// it, as warm runners are matched by template name.
+func poolRunnerSpec(template TemplateOptions, overrides ResourceOverrides) string {
+	strategy := template.DiskRestoreStrategy
+	if strategy == "" {
+		strategy = DiskRestoreSnapshot
+	}
+
+	var parts []string
+
+	if overrides.CPUCores > 0 {
+		parts = append(parts, "cpu-cores="+strconv.FormatUint(uint64(overrides.CPUCores), 10))
+	}
+
+	if overrides.Memory != nil {
+		parts = append(parts, "memory="+overrides.Memory.String())
+	}
+
+	if overrides.DiskSize != nil {
+		parts = append(parts, "disk-size="+overrides.DiskSize.String())
+	}
+
+	parts = append(parts, "disk-restore-strategy="+string(strategy))
+
+	return strings.Join(parts, ",")
+}
+
+// warmRunnerSpec returns the PoolSpecAnnotation of a warm runner. A runner
+// without it is treated as created without overrides and with the default
+// disk restore strategy.
+func warmRunnerSpec(vmi *v1.VirtualMachineInstance) string {
+	if spec, found := vmi.Annotations[PoolSpecAnnotation]; found {
+		return spec
+	}
+
+	return poolRunnerSpec(TemplateOptions{}, ResourceOverrides{})
+}
+
+// claimMetadata returns the labels and annotations a claimed runner takes
+// from the job. A job without a runner pod removes the one of the pool, so
+// garbage collection doesn't tie the runner to the pool pod anymore, and the
+// claim time lets it measure the age of the runner from the claim.
+func claimMetadata(management managementMetadata) map[string]any {
+	annotations := make(map[string]any, len(management.annotations)+2)
+	for key, value := range management.annotations {
+		annotations[key] = value
+	}
+
+	annotations[ClaimedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+
+	if _, found := annotations[RunnerPodAnnotation]; !found {
+		annotations[RunnerPodAnnotation] = nil
+	}
+
+	return map[string]any{
+		"labels":      management.labels,
+		"annotations": annotations,
+	}
+}
+
+// warmRunners returns the Running and Ready warm runners of the pool created
+// from the template, oldest first.
+func (rc *KubevirtRunner) warmRunners(
+	ctx context.Context,
+	pool, vmTemplate, vmTemplateNamespace string,
+) ([]v1.VirtualMachineInstance, error) {
+	vmis, err := rc.virtClient.VirtualMachineInstance(rc.namespace).List(ctx, k8smetav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{
+			ManagedByLabel:         ManagedByValue,
+			PoolLabel:              labelValue(pool),
+			PoolStateLabel:         PoolStateWarm,
+			TemplateLabel:          labelValue(vmTemplate),
+			TemplateNamespaceLabel: labelValue(vmTemplateNamespace),
+		}).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the runners of the %s pool: %w", pool, err)
+	}
+
+	candidates := slices.DeleteFunc(vmis.Items, func(vmi v1.VirtualMachineInstance) bool {
+		return vmi.DeletionTimestamp != nil || vmi.Status.Phase != v1.Running || !isVMIReady(&vmi)
+	})
+
+	slices.SortFunc(candidates, func(a, b v1.VirtualMachineInstance) int {
+		return a.CreationTimestamp.Compare(b.CreationTimestamp.Time)
+	})
+
+	return candidates, nil
+}
+
+// claimPoolRunner patches the runner with the metadata of the job and makes
+// the runner pod its owner. It returns false when another job claimed or
+// deleted the runner first.
+func (rc *KubevirtRunner) claimPoolRunner(
+	ctx context.Context,
+	vmi *v1.VirtualMachineInstance,
+	metadataPatch map[string]any,
+) (bool, error) {
+	metadata := maps.Clone(metadataPatch)
+	metadata["resourceVersion"] = vmi.ResourceVersion
+	metadata["ownerReferences"] = rc.podOwnerReference()
+
+	patch, err := marshalJSON(map[string]any{"metadata": metadata})
+	if err != nil {
+		return false, fmt.Errorf("cannot encode the claim patch: %w", err)
+	}
+
+	_, err = rc.virtClient.VirtualMachineInstance(rc.namespace).Patch(
+		ctx, vmi.Name, types.MergePatchType, patch, k8smetav1.PatchOptions{})
+
+	switch {
+	case err == nil:
+		return true, nil
+	case k8serrors.IsConflict(err), k8serrors.IsNotFound(err):
+		utils.GetLogger().Printf("Warm runner %s was claimed by another job\n", vmi.Name)
+
+		return false, nil
+	default:
+		return false, fmt.Errorf("cannot claim warm runner %s: %w", vmi.Name, err)
+	}
+}
+
+// adoptPoolRunner hands the DataVolumes of a claimed runner over to the job,
+// writes the JIT config into its runner-info Secret and registers it as the
+// runner of this run.
+func (rc *KubevirtRunner) adoptPoolRunner(
+	ctx context.Context,
+	vmi *v1.VirtualMachineInstance,
+	metadataPatch map[string]any,
+	jitConfig string,
+) error {
+	var secretName string
+
+	dataVolumeNames := make([]string, 0, len(vmi.Spec.Volumes))
+
+	for _, volume := range vmi.Spec.Volumes {
+		switch {
+		case volume.Name == runnerInfoVolume && volume.Secret != nil:
+			secretName = volume.Secret.SecretName
+		case volume.DataVolume != nil:
+			dataVolumeNames = append(dataVolumeNames, volume.DataVolume.Name)
+		}
+	}
+
+	patch, err := marshalJSON(map[string]any{"metadata": metadataPatch})
+	if err != nil {
+		return fmt.Errorf("cannot encode the claim patch: %w", err)
+	}
+
+	for _, dataVolumeName := range dataVolumeNames {
+		_, err = rc.virtClient.CdiClient().CdiV1beta1().DataVolumes(rc.namespace).Patch(
+			ctx, dataVolumeName, types.MergePatchType, patch, k8smetav1.PatchOptions{})
+		if err != nil {
+			return fmt.Errorf("cannot claim data volume %s of warm runner %s: %w", dataVolumeName, vmi.Name, err)
+		}
+	}
+
+	err = rc.updateRunnerInfoSecret(ctx, vmi.Name, secretName, jitConfig)
+	if err != nil {
+		return err
+	}
+
+	NewAppContext(vmi.Name, dataVolumeNames, secretName)
+
+	utils.GetLogger().Printf("Claimed %s warm runner from the %s pool\n", vmi.Name, vmi.Labels[PoolLabel])
+	rc.events.Normalf(EventReasonClaimed, "Claimed warm virtual machine instance %s", vmi.Name)
+
+	return nil
+}
+
+func (rc *KubevirtRunner) updateRunnerInfoSecret(ctx context.Context, vmiName, secretName, jitConfig string) error {
+	if secretName == "" {
+		return fmt.Errorf("%w: warm runner %s has no %s volume", ErrNoWarmRunner, vmiName, runnerInfoVolume)
+	}
+
+	payload, err := newRunnerInfoSecret(vmiName, jitConfig)
+	if err != nil {
+		return err
+	}
+
+	secrets := rc.virtClient.CoreV1().Secrets(rc.namespace)
+
+	secret, err := secrets.Get(ctx, secretName, k8smetav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot get the runner info secret of %s: %w", vmiName, err)
+	}
+
+	secret.Data = payload.Data
+
+	_, err = secrets.Update(ctx, secret, k8smetav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot inject the jit config into %s: %w", vmiName, err)
+	}
+
+	return nil
+}
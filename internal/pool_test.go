@@ -0,0 +1,301 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner_test
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	k8sv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime" //nolint:depguard // required by fake reactor signature
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing" //nolint:depguard // required by fake reactor signature
+	v1 "kubevirt.io/api/core/v1"
+	cdifake "kubevirt.io/client-go/containerizeddataimporter/fake"
+	"kubevirt.io/client-go/kubecli"
+	kubevirtfake "kubevirt.io/client-go/kubevirt/fake"
+)
+
+var _ = Describe("Warm pool", func() {
+	const (
+		pool     = "windows"
+		template = "windows-template"
+	)
+
+	var virtClientset *kubevirtfake.Clientset
+
+	var k8sClientset *k8sfake.Clientset
+
+	var pooler runner.Pooler
+
+	spec := runner.PoolSpec{Name: pool, Template: template, TemplateNamespace: k8sv1.NamespaceDefault, Size: 2}
+
+	const largeRunnerSpec = "cpu-cores=4,memory=8Gi,disk-restore-strategy=clone"
+
+	largeOverrides := func() runner.ResourceOverrides {
+		overrides, err := runner.NewResourceOverrides(4, "8Gi", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		return overrides
+	}
+
+	largeTemplate := runner.TemplateOptions{DiskRestoreStrategy: runner.DiskRestoreClone}
+
+	poolRunner := func(name, state string, phase v1.VirtualMachineInstancePhase, age time.Duration) {
+		vmi := NewVirtualMachineInstanceReady(name)
+		vmi.Status.Phase = phase
+		vmi.CreationTimestamp = metav1.NewTime(time.Now().Add(-age))
+		vmi.Labels = map[string]string{
+			runner.ManagedByLabel:         runner.ManagedByValue,
+			runner.PoolLabel:              pool,
+			runner.PoolStateLabel:         state,
+			runner.TemplateLabel:          template,
+			runner.TemplateNamespaceLabel: k8sv1.NamespaceDefault,
+		}
+		vmi.Spec.Volumes = []v1.Volume{{
+			Name: "runner-info",
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{SecretName: "runner-info-" + name},
+			},
+		}}
+
+		Expect(virtClientset.Tracker().Add(vmi)).To(Succeed())
+		Expect(k8sClientset.Tracker().Add(NewSecret("runner-info-" + name))).To(Succeed())
+	}
+
+	listRunners := func(state string) []v1.VirtualMachineInstance {
+		list, err := virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).List(
+			context.TODO(), metav1.ListOptions{LabelSelector: runner.PoolStateLabel + "=" + state})
+		Expect(err).NotTo(HaveOccurred())
+
+		return list.Items
+	}
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		virtClient := kubecli.NewMockKubevirtClient(mockCtrl)
+
+		virtClientset = kubevirtfake.NewSimpleClientset(NewVirtualMachine(template))
+		k8sClientset = k8sfake.NewSimpleClientset()
+
+		virtClient.EXPECT().CdiClient().Return(cdifake.NewSimpleClientset()).AnyTimes()
+		virtClient.EXPECT().CoreV1().Return(k8sClientset.CoreV1()).AnyTimes()
+		virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault)).AnyTimes()
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault)).AnyTimes()
+
+		pooler = runner.NewRunner(k8sv1.NamespaceDefault, virtClient, time.Minute)
+	})
+
+	AfterEach(func() {
+		runner.CancelAppContext()
+	})
+
+	Context("when reconciling", func() {
+		It("creates the missing warm runners", func() {
+			status, err := pooler.ReconcilePool(context.TODO(), spec)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(runner.PoolStatus{Warm: 2, Created: 2}))
+
+			warm := listRunners(runner.PoolStateWarm)
+			Expect(warm).To(HaveLen(2))
+			Expect(warm[0].Name).To(HavePrefix(pool + "-"))
+			Expect(warm[0].Labels).To(HaveKeyWithValue(runner.PoolLabel, pool))
+			Expect(warm[0].Spec.Domain.Devices.Filesystems).To(ContainElement(
+				HaveField("Name", "runner-info")))
+
+			secret, err := k8sClientset.CoreV1().Secrets(k8sv1.NamespaceDefault).Get(
+				context.TODO(), "runner-info-"+warm[0].Name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secret.Data).To(HaveKeyWithValue("runner-info.json", []byte(`{"jitconfig":""}`)))
+		})
+
+		It("records the overrides and the disk restore strategy of the pool on its runners", func() {
+			large := spec
+			large.Size = 1
+			large.Overrides = largeOverrides()
+			large.TemplateOptions = largeTemplate
+
+			_, err := pooler.ReconcilePool(context.TODO(), large)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listRunners(runner.PoolStateWarm)).To(ConsistOf(
+				HaveField("Annotations", HaveKeyWithValue(runner.PoolSpecAnnotation, largeRunnerSpec))))
+		})
+
+		It("replaces the stopped warm runners and leaves the claimed ones alone", func() {
+			poolRunner("windows-ready", runner.PoolStateWarm, v1.Running, time.Hour)
+			poolRunner("windows-failed", runner.PoolStateWarm, v1.Failed, time.Hour)
+			poolRunner("windows-claimed", runner.PoolStateClaimed, v1.Succeeded, time.Hour)
+
+			status, err := pooler.ReconcilePool(context.TODO(), spec)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(runner.PoolStatus{Warm: 2, Ready: 1, Claimed: 1, Created: 1, Deleted: 1}))
+			Expect(listRunners(runner.PoolStateWarm)).NotTo(ContainElement(HaveField("Name", "windows-failed")))
+			Expect(listRunners(runner.PoolStateClaimed)).To(HaveLen(1))
+		})
+
+		It("deletes the newest warm runners when the pool shrinks", func() {
+			poolRunner("windows-old", runner.PoolStateWarm, v1.Running, time.Hour)
+			poolRunner("windows-new", runner.PoolStateWarm, v1.Scheduling, time.Minute)
+
+			shrunk := spec
+			shrunk.Size = 1
+
+			status, err := pooler.ReconcilePool(context.TODO(), shrunk)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(runner.PoolStatus{Warm: 1, Ready: 1, Deleted: 1}))
+			Expect(listRunners(runner.PoolStateWarm)).To(ConsistOf(HaveField("Name", "windows-old")))
+		})
+
+		It("rejects invalid pools", func() {
+			invalid := spec
+			invalid.Name = "Invalid_Name"
+
+			_, err := pooler.ReconcilePool(context.TODO(), invalid)
+
+			Expect(err).To(MatchError(runner.ErrInvalidPoolSpec))
+		})
+	})
+
+	Context("when claiming", func() {
+		claimWith := func(options runner.TemplateOptions, overrides runner.ResourceOverrides) error {
+			return pooler.ClaimRunner(context.TODO(), pool, template, k8sv1.NamespaceDefault, "job-runner", "jit",
+				options, overrides, runner.ResourceMetadata{GitHubRunID: "42"})
+		}
+
+		claim := func() error {
+			return claimWith(runner.TemplateOptions{}, runner.ResourceOverrides{})
+		}
+
+		It("adopts the oldest ready runner and injects the jit config", func() {
+			poolRunner("windows-booting", runner.PoolStateWarm, v1.Scheduled, 2*time.Hour)
+			poolRunner("windows-new", runner.PoolStateWarm, v1.Running, time.Minute)
+			poolRunner("windows-old", runner.PoolStateWarm, v1.Running, time.Hour)
+
+			Expect(claim()).To(Succeed())
+			Expect(runner.GetAppContext().GetVMIName()).To(Equal("windows-old"))
+
+			claimed := listRunners(runner.PoolStateClaimed)
+			Expect(claimed).To(ConsistOf(HaveField("Name", "windows-old")))
+			Expect(claimed[0].Labels).To(HaveKeyWithValue(runner.RunnerNameLabel, "job-runner"))
+			Expect(claimed[0].Labels).To(HaveKeyWithValue(runner.GitHubRunIDLabel, "42"))
+			Expect(claimed[0].Annotations).To(HaveKey(runner.ClaimedAtAnnotation))
+
+			secret, err := k8sClientset.CoreV1().Secrets(k8sv1.NamespaceDefault).Get(
+				context.TODO(), "runner-info-windows-old", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			var runnerInfo map[string]string
+			Expect(json.Unmarshal(secret.Data["runner-info.json"], &runnerInfo)).To(Succeed())
+			Expect(runnerInfo).To(HaveKeyWithValue("jitconfig", "jit"))
+		})
+
+		It("skips the runners claimed by another job", func() {
+			poolRunner("windows-contended", runner.PoolStateWarm, v1.Running, time.Hour)
+			poolRunner("windows-free", runner.PoolStateWarm, v1.Running, time.Minute)
+
+			virtClientset.PrependReactor("patch", "virtualmachineinstances",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					patch, _ := action.(k8stesting.PatchAction)
+					if patch.GetName() != "windows-contended" {
+						return false, nil, nil
+					}
+
+					return true, nil, k8serrors.NewConflict(
+						schema.GroupResource{Group: "kubevirt.io", Resource: "virtualmachineinstances"},
+						patch.GetName(), nil)
+				})
+
+			Expect(claim()).To(Succeed())
+			Expect(runner.GetAppContext().GetVMIName()).To(Equal("windows-free"))
+		})
+
+		It("skips the runners that cannot be adopted", func() {
+			poolRunner("windows-broken", runner.PoolStateWarm, v1.Running, time.Hour)
+			poolRunner("windows-free", runner.PoolStateWarm, v1.Running, time.Minute)
+			Expect(k8sClientset.Tracker().Delete(k8sv1.SchemeGroupVersion.WithResource("secrets"),
+				k8sv1.NamespaceDefault, "runner-info-windows-broken")).To(Succeed())
+
+			Expect(claim()).To(Succeed())
+			Expect(runner.GetAppContext().GetVMIName()).To(Equal("windows-free"))
+
+			_, err := virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).Get(
+				context.TODO(), "windows-broken", metav1.GetOptions{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("reports no warm runner when the claims fail", func() {
+			poolRunner("windows-old", runner.PoolStateWarm, v1.Running, time.Hour)
+
+			virtClientset.PrependReactor("patch", "virtualmachineinstances",
+				func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, k8serrors.NewServiceUnavailable("simulated claim failure")
+				})
+
+			err := claim()
+
+			Expect(err).To(MatchError(runner.ErrNoWarmRunner))
+			Expect(k8serrors.IsServiceUnavailable(err)).To(BeTrue())
+		})
+
+		It("only adopts the runners created with the same overrides and disk restore strategy", func() {
+			poolRunner("windows-default", runner.PoolStateWarm, v1.Running, time.Hour)
+			poolRunner("windows-large", runner.PoolStateWarm, v1.Running, time.Minute)
+
+			large, err := virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).Get(
+				context.TODO(), "windows-large", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			large.Annotations = map[string]string{runner.PoolSpecAnnotation: largeRunnerSpec}
+			Expect(virtClientset.Tracker().Update(v1.SchemeGroupVersion.WithResource("virtualmachineinstances"),
+				large, k8sv1.NamespaceDefault)).To(Succeed())
+
+			Expect(claimWith(largeTemplate, largeOverrides())).To(Succeed())
+			Expect(runner.GetAppContext().GetVMIName()).To(Equal("windows-large"))
+		})
+
+		It("reports no warm runner when none was created with the overrides", func() {
+			poolRunner("windows-default", runner.PoolStateWarm, v1.Running, time.Hour)
+
+			err := claimWith(runner.TemplateOptions{}, largeOverrides())
+
+			Expect(err).To(MatchError(runner.ErrNoWarmRunner))
+			Expect(listRunners(runner.PoolStateWarm)).To(HaveLen(1))
+		})
+
+		It("reports when no warm runner is ready", func() {
+			poolRunner("windows-booting", runner.PoolStateWarm, v1.Scheduling, time.Hour)
+
+			Expect(claim()).To(MatchError(runner.ErrNoWarmRunner))
+		})
+	})
+})
@@ -0,0 +1,155 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// PreserveUntilAnnotation records, in RFC 3339 format, until when a failed
+// runner is kept for debugging. Garbage collection skips the resources until
+// then.
+const PreserveUntilAnnotation = "electrocucaracha.kubevirt-actions-runner/preserve-until"
+
+// Preserver keeps the resources of a failed runner for debugging instead of
+// deleting them.
+type Preserver interface {
+	PreserveResources(ctx context.Context, ttl time.Duration) error
+}
+
+var _ Preserver = (*KubevirtRunner)(nil)
+
+// PreserveResources annotates the runner resources with their expiry and
+// detaches them from the runner pod, so neither the cleanup nor the pod
+// deletion removes them before ttl expires.
+func (rc *KubevirtRunner) PreserveResources(ctx context.Context, ttl time.Duration) error {
+	tracer := otel.Tracer(tracerName)
+
+	ctx, span := tracer.Start(ctx, "PreserveResources",
+		trace.WithAttributes(attribute.String("ttl", ttl.String())),
+	)
+	defer span.End()
+
+	if !HasAppContext() {
+		return nil
+	}
+
+	appCtx := GetAppContext()
+	vmiName := appCtx.GetVMIName()
+	expiry := time.Now().Add(ttl).UTC().Format(time.RFC3339)
+
+	// Only the top-level resource is owned by the runner pod; the others are
+	// owned by it and must keep their owner references.
+	ownerPatch, err := preservePatch(expiry, true)
+	if err != nil {
+		return err
+	}
+
+	annotationPatch, err := preservePatch(expiry, false)
+	if err != nil {
+		return err
+	}
+
+	vmiPatch := ownerPatch
+
+	if rc.virtualMachine {
+		_, err = rc.virtClient.VirtualMachine(rc.namespace).Patch(
+			ctx, vmiName, types.MergePatchType, ownerPatch, k8smetav1.PatchOptions{})
+		if err != nil {
+			span.RecordError(err)
+
+			return fmt.Errorf("cannot preserve runner virtual machine %s: %w", vmiName, err)
+		}
+
+		vmiPatch = annotationPatch
+	}
+
+	_, err = rc.virtClient.VirtualMachineInstance(rc.namespace).Patch(
+		ctx, vmiName, types.MergePatchType, vmiPatch, k8smetav1.PatchOptions{})
+	if err != nil {
+		span.RecordError(err)
+
+		return fmt.Errorf("cannot preserve runner instance %s: %w", vmiName, err)
+	}
+
+	for _, dataVolumeName := range appCtx.GetDataVolumeNames() {
+		// The VirtualMachine controller may not have created every DataVolume yet.
+		_, err = rc.virtClient.CdiClient().CdiV1beta1().DataVolumes(rc.namespace).Patch(
+			ctx, dataVolumeName, types.MergePatchType, annotationPatch, k8smetav1.PatchOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			span.RecordError(err)
+
+			return fmt.Errorf("cannot preserve runner data volume %s: %w", dataVolumeName, err)
+		}
+	}
+
+	// Without an application context the cleanup leaves the resources alone.
+	CancelAppContext()
+
+	log := utils.GetLogger()
+	log.Printf("Preserving %s Virtual Machine Instance until %s\n", vmiName, expiry)
+	log.Printf("Connect to it with: virtctl console %s -n %s\n", vmiName, rc.namespace)
+	rc.events.Normalf(EventReasonPreserved, "Preserved virtual machine instance %s until %s", vmiName, expiry)
+	span.AddEvent("resources_preserved", trace.WithAttributes(attribute.String("expiry", expiry)))
+
+	return nil
+}
+
+// preservePatch returns the merge patch that sets the expiry annotation and,
+// when detach is set, drops the owner references.
+func preservePatch(expiry string, detach bool) ([]byte, error) {
+	metadata := map[string]any{
+		"annotations": map[string]string{PreserveUntilAnnotation: expiry},
+	}
+	if detach {
+		metadata["ownerReferences"] = nil
+	}
+
+	patch, err := marshalJSON(map[string]any{"metadata": metadata})
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode the preserve patch: %w", err)
+	}
+
+	return patch, nil
+}
+
+// preservedUntil returns the expiry recorded by PreserveResources, if any.
+func preservedUntil(meta k8smetav1.ObjectMeta) (time.Time, bool) {
+	value, found := meta.Annotations[PreserveUntilAnnotation]
+	if !found {
+		return time.Time{}, false
+	}
+
+	expiry, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return expiry, true
+}
@@ -0,0 +1,240 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	k8scorev1 "k8s.io/api/core/v1"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "kubevirt.io/api/core/v1"
+	"kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+// RedactedJitConfig replaces the JIT config in the rendered runner-info
+// Secret, so the rendered manifests can be shared.
+const RedactedJitConfig = "REDACTED"
+
+// Renderer renders the runner resources without creating them, to debug
+// templates.
+type Renderer interface {
+	RenderResources(ctx context.Context, opts RenderOptions) (*RenderedResources, error)
+}
+
+var _ Renderer = (*KubevirtRunner)(nil)
+
+// RenderOptions describes the runner to render, as passed to
+// CreateResources.
+type RenderOptions struct {
+	VMTemplate          string
+	VMTemplateNamespace string
+	RunnerName          string
+	Template            TemplateOptions
+	Overrides           ResourceOverrides
+	Metadata            ResourceMetadata
+	// ServerDryRun submits the resources to the API server in dry-run mode,
+	// so they are validated and defaulted by its admission webhooks.
+	ServerDryRun bool
+}
+
+// RenderedResources are the resources CreateResources would create for a
+// runner. Only one of VirtualMachine and VirtualMachineInstance is set,
+// depending on the provisioning mode.
+type RenderedResources struct {
+	VirtualMachine         *v1.VirtualMachine
+	VirtualMachineInstance *v1.VirtualMachineInstance
+	DataVolumes            []*v1beta1.DataVolume
+	Secret                 *k8scorev1.Secret
+}
+
+// Objects returns the rendered resources in creation order.
+func (r *RenderedResources) Objects() []any {
+	objects := make([]any, 0, 2+len(r.DataVolumes)) //nolint:mnd // the VM or VMI and the Secret
+
+	if r.VirtualMachine != nil {
+		objects = append(objects, r.VirtualMachine)
+	}
+
+	if r.VirtualMachineInstance != nil {
+		objects = append(objects, r.VirtualMachineInstance)
+	}
+
+	for _, dataVolume := range r.DataVolumes {
+		objects = append(objects, dataVolume)
+	}
+
+	return append(objects, r.Secret)
+}
+
+func (rc *KubevirtRunner) RenderResources(ctx context.Context, opts RenderOptions) (*RenderedResources, error) {
+	tracer := otel.Tracer(tracerName)
+
+	if opts.VMTemplateNamespace == "" {
+		opts.VMTemplateNamespace = k8scorev1.NamespaceDefault
+	}
+
+	ctx, span := tracer.Start(ctx, "RenderResources",
+		trace.WithAttributes(
+			attribute.String("vmTemplate", opts.VMTemplate),
+			attribute.String("vmTemplateNamespace", opts.VMTemplateNamespace),
+			attribute.String("runnerName", opts.RunnerName),
+			attribute.String("namespace", rc.namespace),
+			attribute.Bool("serverDryRun", opts.ServerDryRun),
+		),
+	)
+	defer span.End()
+
+	rendered, err := rc.renderResources(ctx, opts, span)
+	if err != nil {
+		span.RecordError(err)
+
+		return nil, err
+	}
+
+	if opts.ServerDryRun {
+		err = rc.dryRunResources(ctx, rendered)
+		if err != nil {
+			span.RecordError(err)
+
+			return nil, err
+		}
+	}
+
+	decodeSecretData(rendered.Secret)
+
+	return rendered, nil
+}
+
+func (rc *KubevirtRunner) renderResources(
+	ctx context.Context,
+	opts RenderOptions,
+	span trace.Span,
+) (*RenderedResources, error) {
+	err := rc.validateResourceInputs(opts.VMTemplate, opts.RunnerName, RedactedJitConfig, span)
+	if err != nil {
+		return nil, err
+	}
+
+	err = rc.resourceLimits.validate(opts.Overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	err = opts.Metadata.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	management := rc.managementMetadata(opts.VMTemplate, opts.VMTemplateNamespace, opts.RunnerName, opts.Metadata)
+	rendered := &RenderedResources{}
+
+	if rc.virtualMachine {
+		rendered.VirtualMachine, rendered.Secret, err = rc.getVirtualMachineResources(ctx,
+			opts.VMTemplate, opts.VMTemplateNamespace, opts.RunnerName, RedactedJitConfig,
+			opts.Template, opts.Overrides, management)
+		if err != nil {
+			return nil, err
+		}
+
+		rendered.VirtualMachine.OwnerReferences = rc.podOwnerReference()
+		rendered.VirtualMachine.SetGroupVersionKind(v1.VirtualMachineGroupVersionKind)
+	} else {
+		rendered.VirtualMachineInstance, rendered.DataVolumes, rendered.Secret, err = rc.getResources(ctx,
+			opts.VMTemplate, opts.VMTemplateNamespace, opts.RunnerName, RedactedJitConfig,
+			opts.Template, opts.Overrides, management)
+		if err != nil {
+			return nil, err
+		}
+
+		rendered.VirtualMachineInstance.OwnerReferences = rc.podOwnerReference()
+	}
+
+	for _, dataVolume := range rendered.DataVolumes {
+		dataVolume.Namespace = rc.namespace
+		dataVolume.SetGroupVersionKind(v1beta1.SchemeGroupVersion.WithKind("DataVolume"))
+	}
+
+	rendered.Secret.Namespace = rc.namespace
+	rendered.Secret.SetGroupVersionKind(k8scorev1.SchemeGroupVersion.WithKind("Secret"))
+
+	return rendered, nil
+}
+
+// decodeSecretData moves the Secret payload into its string data, so it's
+// shown as is rather than base64 encoded; its only secret is redacted.
+func decodeSecretData(secret *k8scorev1.Secret) {
+	secret.StringData = make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		secret.StringData[key] = string(value)
+	}
+
+	secret.Data = nil
+}
+
+// dryRunResources submits the rendered resources to the API server in
+// dry-run mode, replacing them with the objects it would have persisted.
+func (rc *KubevirtRunner) dryRunResources(ctx context.Context, rendered *RenderedResources) error {
+	options := k8smetav1.CreateOptions{DryRun: []string{k8smetav1.DryRunAll}}
+
+	if rendered.VirtualMachine != nil {
+		vm, err := rc.virtClient.VirtualMachine(rc.namespace).Create(ctx, rendered.VirtualMachine, options)
+		if err != nil {
+			return fmt.Errorf("virtual machine %s was rejected: %w", rendered.VirtualMachine.Name, err)
+		}
+
+		vm.SetGroupVersionKind(v1.VirtualMachineGroupVersionKind)
+		rendered.VirtualMachine = vm
+	}
+
+	if rendered.VirtualMachineInstance != nil {
+		vmi, err := rc.virtClient.VirtualMachineInstance(rc.namespace).Create(
+			ctx, rendered.VirtualMachineInstance, options)
+		if err != nil {
+			return fmt.Errorf("virtual machine instance %s was rejected: %w", rendered.VirtualMachineInstance.Name, err)
+		}
+
+		vmi.SetGroupVersionKind(v1.VirtualMachineInstanceGroupVersionKind)
+		rendered.VirtualMachineInstance = vmi
+	}
+
+	for i, dataVolume := range rendered.DataVolumes {
+		created, err := rc.virtClient.CdiClient().CdiV1beta1().DataVolumes(rc.namespace).Create(
+			ctx, dataVolume, options)
+		if err != nil {
+			return fmt.Errorf("data volume %s was rejected: %w", dataVolume.Name, err)
+		}
+
+		created.SetGroupVersionKind(dataVolume.GroupVersionKind())
+		rendered.DataVolumes[i] = created
+	}
+
+	secret, err := rc.virtClient.CoreV1().Secrets(rc.namespace).Create(ctx, rendered.Secret, options)
+	if err != nil {
+		return fmt.Errorf("secret %s was rejected: %w", rendered.Secret.Name, err)
+	}
+
+	secret.SetGroupVersionKind(rendered.Secret.GroupVersionKind())
+	rendered.Secret = secret
+
+	return nil
+}
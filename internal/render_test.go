@@ -0,0 +1,189 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner_test
+
+import (
+	"context"
+	"time"
+
+	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime" //nolint:depguard // required by fake reactor signature
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing" //nolint:depguard // required by fake reactor signature
+	v1 "kubevirt.io/api/core/v1"
+	cdifake "kubevirt.io/client-go/containerizeddataimporter/fake"
+	"kubevirt.io/client-go/kubecli"
+	kubevirtfake "kubevirt.io/client-go/kubevirt/fake"
+	"kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+var _ = Describe("Render", func() {
+	const (
+		vmTemplate = "vm-template"
+		runnerName = "runner-render"
+	)
+
+	var (
+		renderer      runner.Renderer
+		virtClient    *kubecli.MockKubevirtClient
+		virtClientset *kubevirtfake.Clientset
+		cdiClientset  *cdifake.Clientset
+		k8sClientset  *k8sfake.Clientset
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		virtClient = kubecli.NewMockKubevirtClient(mockCtrl)
+		virtClientset = kubevirtfake.NewSimpleClientset(NewVirtualMachineWithDataVolumes(vmTemplate, "boot-disk"))
+		cdiClientset = cdifake.NewSimpleClientset()
+		k8sClientset = k8sfake.NewSimpleClientset()
+
+		virtClient.EXPECT().CdiClient().Return(cdiClientset).AnyTimes()
+		virtClient.EXPECT().CoreV1().Return(k8sClientset.CoreV1()).AnyTimes()
+		virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault)).AnyTimes()
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault)).AnyTimes()
+
+		renderer = runner.NewRunner(k8sv1.NamespaceDefault, virtClient, time.Minute)
+	})
+
+	It("renders the runner resources with the jit config redacted without creating them", func() {
+		rendered, err := renderer.RenderResources(context.TODO(), runner.RenderOptions{
+			VMTemplate: vmTemplate,
+			RunnerName: runnerName,
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rendered.VirtualMachine).To(BeNil())
+		Expect(rendered.VirtualMachineInstance.Name).To(Equal(runnerName))
+		Expect(rendered.VirtualMachineInstance.Kind).To(Equal("VirtualMachineInstance"))
+		Expect(rendered.DataVolumes).To(ConsistOf(SatisfyAll(
+			HaveField("Name", "boot-disk-"+runnerName),
+			HaveField("Namespace", k8sv1.NamespaceDefault),
+			HaveField("Kind", "DataVolume"),
+		)))
+		Expect(rendered.Secret.Data).To(BeEmpty())
+		Expect(rendered.Secret.StringData).To(HaveKeyWithValue("runner-info.json",
+			`{"jitconfig":"`+runner.RedactedJitConfig+`"}`))
+		Expect(rendered.Objects()).To(HaveLen(3))
+
+		vmis, err := virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).List(
+			context.TODO(), metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vmis.Items).To(BeEmpty())
+		Expect(cdiClientset.Actions()).To(BeEmpty())
+		Expect(k8sClientset.Actions()).To(BeEmpty())
+	})
+
+	It("submits the resources to the API server in dry-run mode", func() {
+		var dryRuns []string
+
+		dryRun := func(action k8stesting.Action) (bool, runtime.Object, error) {
+			create, ok := action.(k8stesting.CreateActionImpl)
+			Expect(ok).To(BeTrue())
+			Expect(create.CreateOptions.DryRun).To(ConsistOf(metav1.DryRunAll))
+
+			dryRuns = append(dryRuns, action.GetResource().Resource)
+
+			return true, create.GetObject(), nil
+		}
+		virtClientset.PrependReactor("create", "virtualmachineinstances",
+			func(action k8stesting.Action) (bool, runtime.Object, error) {
+				handled, object, err := dryRun(action)
+				object.(*v1.VirtualMachineInstance).Annotations = map[string]string{"defaulted": "true"}
+
+				return handled, object, err
+			})
+		cdiClientset.PrependReactor("create", "datavolumes", dryRun)
+		k8sClientset.PrependReactor("create", "secrets", dryRun)
+
+		rendered, err := renderer.RenderResources(context.TODO(), runner.RenderOptions{
+			VMTemplate:   vmTemplate,
+			RunnerName:   runnerName,
+			ServerDryRun: true,
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dryRuns).To(Equal([]string{"virtualmachineinstances", "datavolumes", "secrets"}))
+		Expect(rendered.VirtualMachineInstance.Annotations).To(HaveKeyWithValue("defaulted", "true"))
+		Expect(rendered.VirtualMachineInstance.Kind).To(Equal("VirtualMachineInstance"))
+		Expect(rendered.Secret.StringData).To(HaveKey("runner-info.json"))
+	})
+
+	DescribeTable("sizes the data volume of the boot disk", func(opts ...runner.Option) {
+		const bootTemplate = "boot-order-template"
+
+		template := NewVirtualMachineWithDataVolumes(bootTemplate, "scratch-disk", "root-disk")
+		template.Spec.Template.Spec.Domain.Devices.Disks = []v1.Disk{
+			{Name: "disk0"},
+			{Name: "disk1", BootOrder: new(uint(1))},
+		}
+		Expect(virtClientset.Tracker().Add(template)).To(Succeed())
+
+		overrides, err := runner.NewResourceOverrides(0, "", "50Gi")
+		Expect(err).NotTo(HaveOccurred())
+
+		rendered, err := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, time.Minute, opts...).RenderResources(
+			context.TODO(), runner.RenderOptions{VMTemplate: bootTemplate, RunnerName: runnerName, Overrides: overrides})
+		Expect(err).NotTo(HaveOccurred())
+
+		sizes := map[string]string{}
+
+		for _, dataVolume := range rendered.DataVolumes {
+			sizes[dataVolume.Name] = storageRequest(dataVolume.Spec)
+		}
+
+		if rendered.VirtualMachine != nil {
+			for _, dvt := range rendered.VirtualMachine.Spec.DataVolumeTemplates {
+				sizes[dvt.Name] = storageRequest(dvt.Spec)
+			}
+		}
+
+		Expect(sizes).To(Equal(map[string]string{
+			"scratch-disk-" + runnerName: "",
+			"root-disk-" + runnerName:    "50Gi",
+		}))
+	},
+		Entry("when the runner is a VMI"),
+		Entry("when the runner is a virtual machine", runner.WithVirtualMachine()),
+	)
+
+	It("fails when the template doesn't exist", func() {
+		_, err := renderer.RenderResources(context.TODO(), runner.RenderOptions{
+			VMTemplate: "missing-template",
+			RunnerName: runnerName,
+		})
+
+		Expect(err).To(MatchError(runner.ErrTemplateNotFound))
+	})
+})
+
+func storageRequest(spec v1beta1.DataVolumeSpec) string {
+	if spec.Storage == nil {
+		return ""
+	}
+
+	return spec.Storage.Resources.Requests.Storage().String()
+}
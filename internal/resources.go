@@ -0,0 +1,256 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"fmt"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
+	k8scorev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "kubevirt.io/api/core/v1"
+	"kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+// ResourceOverrides adjusts how a single runner is built from its template.
+// Zero values keep the template settings.
+type ResourceOverrides struct {
+	// CPUCores sets the number of guest vCPUs, as a single socket of cores
+	// with one thread each.
+	CPUCores uint32
+	// Memory replaces the guest memory and the memory resources of the VMI.
+	Memory *resource.Quantity
+	// DiskSize replaces the storage request of the DataVolumeTemplate behind
+	// the boot disk.
+	DiskSize *resource.Quantity
+}
+
+// NewResourceOverrides parses the memory and disk size quantities of the
+// overrides; empty strings leave them unset.
+func NewResourceOverrides(cpuCores uint32, memory, diskSize string) (ResourceOverrides, error) {
+	overrides := ResourceOverrides{CPUCores: cpuCores}
+
+	var err error
+
+	overrides.Memory, err = parseOptionalQuantity("memory", memory)
+	if err != nil {
+		return ResourceOverrides{}, err
+	}
+
+	overrides.DiskSize, err = parseOptionalQuantity("disk size", diskSize)
+	if err != nil {
+		return ResourceOverrides{}, err
+	}
+
+	return overrides, nil
+}
+
+func parseOptionalQuantity(name, value string) (*resource.Quantity, error) {
+	if value == "" {
+		return nil, nil //nolint:nilnil // an empty value leaves the override unset
+	}
+
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %q: %w", ErrInvalidResourceOverride, name, value, err)
+	}
+
+	return &quantity, nil
+}
+
+// ResourceLimits bounds the values accepted as ResourceOverrides. Zero and
+// nil bounds are not enforced.
+type ResourceLimits struct {
+	MinCPUCores uint32
+	MaxCPUCores uint32
+	MinMemory   *resource.Quantity
+	MaxMemory   *resource.Quantity
+	MinDiskSize *resource.Quantity
+	MaxDiskSize *resource.Quantity
+}
+
+// validate reports an ErrInvalidResourceOverride when an override falls
+// outside of the limits.
+func (l ResourceLimits) validate(overrides ResourceOverrides) error {
+	if overrides.CPUCores != 0 {
+		if l.MinCPUCores != 0 && overrides.CPUCores < l.MinCPUCores {
+			return fmt.Errorf("%w: %d cpu cores is below the minimum of %d",
+				ErrInvalidResourceOverride, overrides.CPUCores, l.MinCPUCores)
+		}
+
+		if l.MaxCPUCores != 0 && overrides.CPUCores > l.MaxCPUCores {
+			return fmt.Errorf("%w: %d cpu cores is above the maximum of %d",
+				ErrInvalidResourceOverride, overrides.CPUCores, l.MaxCPUCores)
+		}
+	}
+
+	err := validateQuantity("memory", overrides.Memory, l.MinMemory, l.MaxMemory)
+	if err != nil {
+		return err
+	}
+
+	return validateQuantity("disk size", overrides.DiskSize, l.MinDiskSize, l.MaxDiskSize)
+}
+
+func validateQuantity(name string, value, minimum, maximum *resource.Quantity) error {
+	if value == nil {
+		return nil
+	}
+
+	if minimum != nil && value.Cmp(*minimum) < 0 {
+		return fmt.Errorf("%w: %s %s is below the minimum of %s",
+			ErrInvalidResourceOverride, name, value, minimum)
+	}
+
+	if maximum != nil && value.Cmp(*maximum) > 0 {
+		return fmt.Errorf("%w: %s %s is above the maximum of %s",
+			ErrInvalidResourceOverride, name, value, maximum)
+	}
+
+	return nil
+}
+
+// applyToSpec sizes the guest CPU and memory of a VMI spec.
+func (o ResourceOverrides) applyToSpec(spec *v1.VirtualMachineInstanceSpec) {
+	if o.CPUCores != 0 {
+		if spec.Domain.CPU == nil {
+			spec.Domain.CPU = &v1.CPU{}
+		}
+
+		// The guest gets sockets x cores x threads vCPUs, so the topology of
+		// the template is flattened for CPUCores to be the total.
+		spec.Domain.CPU.Sockets = 1
+		spec.Domain.CPU.Cores = o.CPUCores
+		spec.Domain.CPU.Threads = 1
+	}
+
+	if o.Memory != nil {
+		guest := o.Memory.DeepCopy()
+
+		if spec.Domain.Memory == nil {
+			spec.Domain.Memory = &v1.Memory{}
+		}
+
+		spec.Domain.Memory.Guest = &guest
+
+		// Keep the pod resources consistent with the new guest memory when
+		// the template sets them explicitly.
+		replaceResource(spec.Domain.Resources.Requests, k8scorev1.ResourceMemory, guest)
+		replaceResource(spec.Domain.Resources.Limits, k8scorev1.ResourceMemory, guest)
+	}
+}
+
+// applyToBootDataVolume sets the storage request of the DataVolumeTemplate
+// behind the boot disk of a VirtualMachine.
+func (o ResourceOverrides) applyToBootDataVolume(spec *v1.VirtualMachineSpec) {
+	if o.DiskSize == nil {
+		return
+	}
+
+	name, found := bootDataVolumeTemplate(spec)
+	if !found {
+		utils.GetLogger().Warnf("disk size %s not applied: the boot disk doesn't come from a DataVolumeTemplate",
+			o.DiskSize)
+
+		return
+	}
+
+	for i := range spec.DataVolumeTemplates {
+		if spec.DataVolumeTemplates[i].Name == name {
+			o.applyToDataVolume(&spec.DataVolumeTemplates[i].Spec)
+		}
+	}
+}
+
+// applyToDataVolume sets the storage request of a DataVolume spec.
+func (o ResourceOverrides) applyToDataVolume(spec *v1beta1.DataVolumeSpec) {
+	if o.DiskSize == nil {
+		return
+	}
+
+	size := o.DiskSize.DeepCopy()
+
+	if spec.PVC != nil {
+		if spec.PVC.Resources.Requests == nil {
+			spec.PVC.Resources.Requests = k8scorev1.ResourceList{}
+		}
+
+		spec.PVC.Resources.Requests[k8scorev1.ResourceStorage] = size
+
+		return
+	}
+
+	if spec.Storage == nil {
+		spec.Storage = &v1beta1.StorageSpec{}
+	}
+
+	if spec.Storage.Resources.Requests == nil {
+		spec.Storage.Resources.Requests = k8scorev1.ResourceList{}
+	}
+
+	spec.Storage.Resources.Requests[k8scorev1.ResourceStorage] = size
+}
+
+// bootDataVolumeTemplate returns the name of the DataVolumeTemplate behind
+// the boot disk of a VirtualMachine: the disk with the lowest boot order, or
+// the first disk when none has one. It returns false when the boot disk
+// doesn't come from a DataVolumeTemplate.
+func bootDataVolumeTemplate(spec *v1.VirtualMachineSpec) (string, bool) {
+	if spec.Template == nil {
+		return "", false
+	}
+
+	bootVolume := ""
+
+	disks := spec.Template.Spec.Domain.Devices.Disks
+	if len(disks) > 0 {
+		boot := disks[0]
+
+		for _, disk := range disks {
+			if disk.BootOrder != nil && (boot.BootOrder == nil || *disk.BootOrder < *boot.BootOrder) {
+				boot = disk
+			}
+		}
+
+		bootVolume = boot.Name
+	} else if len(spec.Template.Spec.Volumes) > 0 {
+		// KubeVirt adds a disk for every volume, in order, when none is listed.
+		bootVolume = spec.Template.Spec.Volumes[0].Name
+	}
+
+	for _, volume := range spec.Template.Spec.Volumes {
+		if volume.Name != bootVolume || volume.DataVolume == nil {
+			continue
+		}
+
+		for _, dvt := range spec.DataVolumeTemplates {
+			if dvt.Name == volume.DataVolume.Name {
+				return dvt.Name, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func replaceResource(list k8scorev1.ResourceList, name k8scorev1.ResourceName, quantity resource.Quantity) {
+	if _, found := list[name]; found {
+		list[name] = quantity
+	}
+}
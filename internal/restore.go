@@ -0,0 +1,254 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"context"
+	"fmt"
+
+	volumesnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "kubevirt.io/api/core/v1"
+	"kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+const (
+	// SourceVMSnapshotAnnotation names the VirtualMachineSnapshot, in the
+	// namespace of the template, whose volumes the runner disks are restored
+	// from. It is set on the template VirtualMachine.
+	SourceVMSnapshotAnnotation = "electrocucaracha.kubevirt-actions-runner/source-vm-snapshot"
+	// SourceVolumeSnapshotAnnotation names the VolumeSnapshot, in the
+	// namespace of the template, a runner disk is restored from. It is set on
+	// a DataVolumeTemplate and takes precedence over SourceVMSnapshotAnnotation.
+	SourceVolumeSnapshotAnnotation = "electrocucaracha.kubevirt-actions-runner/source-volume-snapshot"
+)
+
+// DiskRestoreStrategy selects how the runner disks are created from the
+// snapshots referenced by the template.
+type DiskRestoreStrategy string
+
+const (
+	// DiskRestoreSnapshot restores the disks from the VolumeSnapshots. It is
+	// the default.
+	DiskRestoreSnapshot DiskRestoreStrategy = "snapshot"
+	// DiskRestoreClone clones the PersistentVolumeClaims the snapshots were
+	// taken from.
+	DiskRestoreClone DiskRestoreStrategy = "clone"
+	// DiskRestoreImport ignores the snapshots and keeps the source of the
+	// DataVolumeTemplates.
+	DiskRestoreImport DiskRestoreStrategy = "import"
+)
+
+// ParseDiskRestoreStrategy validates a disk restore strategy. An empty value
+// is kept, which restoreDiskSources treats as DiskRestoreSnapshot.
+func ParseDiskRestoreStrategy(value string) (DiskRestoreStrategy, error) {
+	switch strategy := DiskRestoreStrategy(value); strategy {
+	case "", DiskRestoreSnapshot, DiskRestoreClone, DiskRestoreImport:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidDiskRestoreStrategy, value)
+	}
+}
+
+// snapshotSource is a snapshot a runner disk can be restored from, along
+// with the claim it was taken from.
+type snapshotSource struct {
+	kind           string
+	name           string
+	volumeSnapshot string
+	claim          string
+}
+
+// restoreDiskSources replaces the source of the DataVolumeTemplates that
+// reference a snapshot, following the strategy, and records the outcome on
+// the span of ctx.
+func (rc *KubevirtRunner) restoreDiskSources(
+	ctx context.Context,
+	template *v1.VirtualMachine,
+	templateNamespace string,
+	strategy DiskRestoreStrategy,
+) error {
+	if strategy == "" {
+		strategy = DiskRestoreSnapshot
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("diskRestoreStrategy", string(strategy)))
+
+	if strategy == DiskRestoreImport {
+		return nil
+	}
+
+	var vmBackups map[string]snapshotSource
+
+	for i := range template.Spec.DataVolumeTemplates {
+		dataVolumeTemplate := &template.Spec.DataVolumeTemplates[i]
+
+		var (
+			source snapshotSource
+			found  bool
+			err    error
+		)
+
+		if name := dataVolumeTemplate.Annotations[SourceVolumeSnapshotAnnotation]; name != "" {
+			source, err = rc.volumeSnapshotSource(ctx, templateNamespace, name)
+			found = true
+		} else if name := template.Annotations[SourceVMSnapshotAnnotation]; name != "" {
+			if vmBackups == nil {
+				vmBackups, err = rc.vmSnapshotSources(ctx, templateNamespace, name, template)
+			}
+
+			source, found = vmBackups[dataVolumeTemplate.Name]
+		}
+
+		if err != nil {
+			span.RecordError(err)
+
+			return err
+		}
+
+		if !found {
+			continue
+		}
+
+		err = restoreDataVolume(&dataVolumeTemplate.Spec, templateNamespace, source, strategy)
+		if err != nil {
+			span.RecordError(err)
+
+			return fmt.Errorf("cannot restore data volume %s: %w", dataVolumeTemplate.Name, err)
+		}
+
+		span.AddEvent("disk_restore", trace.WithAttributes(
+			attribute.String("dataVolumeTemplate", dataVolumeTemplate.Name),
+			attribute.String("sourceKind", source.kind),
+			attribute.String("sourceName", source.name),
+		))
+	}
+
+	return nil
+}
+
+func restoreDataVolume(
+	spec *v1beta1.DataVolumeSpec,
+	namespace string,
+	source snapshotSource,
+	strategy DiskRestoreStrategy,
+) error {
+	switch {
+	case strategy == DiskRestoreClone && source.claim != "":
+		spec.Source = &v1beta1.DataVolumeSource{
+			PVC: &v1beta1.DataVolumeSourcePVC{Namespace: namespace, Name: source.claim},
+		}
+	case strategy == DiskRestoreClone:
+		return fmt.Errorf("%w: %s %s has no source claim to clone", ErrSnapshotNotReady, source.kind, source.name)
+	case source.volumeSnapshot != "":
+		spec.Source = &v1beta1.DataVolumeSource{
+			Snapshot: &v1beta1.DataVolumeSourceSnapshot{Namespace: namespace, Name: source.volumeSnapshot},
+		}
+	default:
+		return fmt.Errorf("%w: %s %s has no volume snapshot", ErrSnapshotNotReady, source.kind, source.name)
+	}
+
+	spec.SourceRef = nil
+
+	return nil
+}
+
+func (rc *KubevirtRunner) volumeSnapshotSource(ctx context.Context, namespace, name string) (snapshotSource, error) {
+	volumeSnapshot, err := rc.virtClient.KubernetesSnapshotClient().SnapshotV1().VolumeSnapshots(namespace).Get(
+		ctx, name, k8smetav1.GetOptions{})
+	if err != nil {
+		return snapshotSource{}, fmt.Errorf("cannot get volume snapshot %s/%s: %w", namespace, name, err)
+	}
+
+	if !isVolumeSnapshotReady(volumeSnapshot) {
+		return snapshotSource{}, fmt.Errorf("%w: volume snapshot %s/%s", ErrSnapshotNotReady, namespace, name)
+	}
+
+	source := snapshotSource{kind: "VolumeSnapshot", name: name, volumeSnapshot: name}
+	if claim := volumeSnapshot.Spec.Source.PersistentVolumeClaimName; claim != nil {
+		source.claim = *claim
+	}
+
+	return source, nil
+}
+
+func isVolumeSnapshotReady(volumeSnapshot *volumesnapshotv1.VolumeSnapshot) bool {
+	return volumeSnapshot.Status != nil && volumeSnapshot.Status.ReadyToUse != nil && *volumeSnapshot.Status.ReadyToUse
+}
+
+// vmSnapshotSources maps the DataVolumeTemplates of the template to the
+// volume backups of a VirtualMachineSnapshot, through the volumes of the
+// template that use them.
+func (rc *KubevirtRunner) vmSnapshotSources(
+	ctx context.Context,
+	namespace, name string,
+	template *v1.VirtualMachine,
+) (map[string]snapshotSource, error) {
+	vmSnapshot, err := rc.virtClient.VirtualMachineSnapshot(namespace).Get(ctx, name, k8smetav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get virtual machine snapshot %s/%s: %w", namespace, name, err)
+	}
+
+	status := vmSnapshot.Status
+	if status == nil || status.ReadyToUse == nil || !*status.ReadyToUse ||
+		status.VirtualMachineSnapshotContentName == nil {
+		return nil, fmt.Errorf("%w: virtual machine snapshot %s/%s", ErrSnapshotNotReady, namespace, name)
+	}
+
+	content, err := rc.virtClient.VirtualMachineSnapshotContent(namespace).Get(
+		ctx, *status.VirtualMachineSnapshotContentName, k8smetav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the content of virtual machine snapshot %s/%s: %w", namespace, name, err)
+	}
+
+	dataVolumes := map[string]string{}
+
+	if template.Spec.Template != nil {
+		for _, volume := range template.Spec.Template.Spec.Volumes {
+			if volume.DataVolume != nil {
+				dataVolumes[volume.Name] = volume.DataVolume.Name
+			}
+		}
+	}
+
+	sources := make(map[string]snapshotSource, len(content.Spec.VolumeBackups))
+
+	for _, backup := range content.Spec.VolumeBackups {
+		dataVolume, found := dataVolumes[backup.VolumeName]
+		if !found {
+			continue
+		}
+
+		source := snapshotSource{
+			kind:  "VirtualMachineSnapshot",
+			name:  name,
+			claim: backup.PersistentVolumeClaim.Name,
+		}
+		if backup.VolumeSnapshotName != nil {
+			source.volumeSnapshot = *backup.VolumeSnapshotName
+		}
+
+		sources[dataVolume] = source
+	}
+
+	return sources, nil
+}
@@ -0,0 +1,195 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner_test
+
+import (
+	"context"
+	"time"
+
+	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	volumesnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	v1 "kubevirt.io/api/core/v1"
+	snapshotv1beta1 "kubevirt.io/api/snapshot/v1beta1"
+	cdifake "kubevirt.io/client-go/containerizeddataimporter/fake"
+	snapshotfake "kubevirt.io/client-go/externalsnapshotter/fake"
+	"kubevirt.io/client-go/kubecli"
+	kubevirtfake "kubevirt.io/client-go/kubevirt/fake"
+	"kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+var _ = Describe("Disk restore", func() {
+	const (
+		vmTemplate = "vm-template"
+		runnerName = "runner-restored"
+		bootDisk   = "boot-disk"
+	)
+
+	var (
+		karRunner        runner.Runner
+		cdiClientset     *cdifake.Clientset
+		snapshotClient   *snapshotfake.Clientset
+		vmSnapshotClient *kubevirtfake.Clientset
+		template         *v1.VirtualMachine
+	)
+
+	volumeSnapshot := func(name, claim string, ready bool) *volumesnapshotv1.VolumeSnapshot {
+		return &volumesnapshotv1.VolumeSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: k8sv1.NamespaceDefault},
+			Spec: volumesnapshotv1.VolumeSnapshotSpec{
+				Source: volumesnapshotv1.VolumeSnapshotSource{PersistentVolumeClaimName: &claim},
+			},
+			Status: &volumesnapshotv1.VolumeSnapshotStatus{ReadyToUse: &ready},
+		}
+	}
+
+	vmSnapshot := func(name string, ready bool) {
+		content := name + "-content"
+
+		Expect(vmSnapshotClient.Tracker().Add(&snapshotv1beta1.VirtualMachineSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: k8sv1.NamespaceDefault},
+			Status: &snapshotv1beta1.VirtualMachineSnapshotStatus{
+				ReadyToUse:                        &ready,
+				VirtualMachineSnapshotContentName: &content,
+			},
+		})).To(Succeed())
+		Expect(vmSnapshotClient.Tracker().Add(&snapshotv1beta1.VirtualMachineSnapshotContent{
+			ObjectMeta: metav1.ObjectMeta{Name: content, Namespace: k8sv1.NamespaceDefault},
+			Spec: snapshotv1beta1.VirtualMachineSnapshotContentSpec{
+				VolumeBackups: []snapshotv1beta1.VolumeBackup{{
+					VolumeName:            "disk0",
+					PersistentVolumeClaim: snapshotv1beta1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "golden-disk"}},
+					VolumeSnapshotName:    new("vmsnapshot-golden-disk"),
+				}},
+			},
+		})).To(Succeed())
+	}
+
+	createResources := func(strategy runner.DiskRestoreStrategy) (*v1beta1.DataVolume, error) {
+		virtClientset := kubevirtfake.NewSimpleClientset(template)
+
+		mockCtrl := gomock.NewController(GinkgoT())
+		virtClient := kubecli.NewMockKubevirtClient(mockCtrl)
+		virtClient.EXPECT().CdiClient().Return(cdiClientset).AnyTimes()
+		virtClient.EXPECT().CoreV1().Return(k8sfake.NewSimpleClientset().CoreV1()).AnyTimes()
+		virtClient.EXPECT().KubernetesSnapshotClient().Return(snapshotClient).AnyTimes()
+		virtClient.EXPECT().VirtualMachineSnapshot(k8sv1.NamespaceDefault).Return(
+			vmSnapshotClient.SnapshotV1beta1().VirtualMachineSnapshots(k8sv1.NamespaceDefault)).AnyTimes()
+		virtClient.EXPECT().VirtualMachineSnapshotContent(k8sv1.NamespaceDefault).Return(
+			vmSnapshotClient.SnapshotV1beta1().VirtualMachineSnapshotContents(k8sv1.NamespaceDefault)).AnyTimes()
+		virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault)).AnyTimes()
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault)).AnyTimes()
+
+		karRunner = runner.NewRunner(k8sv1.NamespaceDefault, virtClient, time.Minute)
+
+		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerName, "jitConfig",
+			runner.TemplateOptions{DiskRestoreStrategy: strategy}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+		if err != nil {
+			return nil, err
+		}
+
+		dataVolumes, err := cdiClientset.CdiV1beta1().DataVolumes(k8sv1.NamespaceDefault).List(
+			context.TODO(), metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dataVolumes.Items).To(HaveLen(1))
+
+		return &dataVolumes.Items[0], nil
+	}
+
+	BeforeEach(func() {
+		cdiClientset = cdifake.NewSimpleClientset()
+		snapshotClient = snapshotfake.NewSimpleClientset(volumeSnapshot("golden", "golden-disk", true))
+		vmSnapshotClient = kubevirtfake.NewSimpleClientset()
+
+		template = NewVirtualMachineWithDataVolumes(vmTemplate, bootDisk)
+		template.Spec.DataVolumeTemplates[0].Spec.Source = &v1beta1.DataVolumeSource{
+			HTTP: &v1beta1.DataVolumeSourceHTTP{URL: "https://example.com/disk.qcow2"},
+		}
+	})
+
+	AfterEach(func() {
+		runner.CancelAppContext()
+	})
+
+	It("restores the disk from the volume snapshot of the data volume template", func() {
+		template.Spec.DataVolumeTemplates[0].Annotations = map[string]string{
+			runner.SourceVolumeSnapshotAnnotation: "golden",
+		}
+
+		dataVolume, err := createResources("")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dataVolume.Spec.Source).To(Equal(&v1beta1.DataVolumeSource{
+			Snapshot: &v1beta1.DataVolumeSourceSnapshot{Namespace: k8sv1.NamespaceDefault, Name: "golden"},
+		}))
+	})
+
+	It("clones the claim the volume snapshot was taken from", func() {
+		template.Spec.DataVolumeTemplates[0].Annotations = map[string]string{
+			runner.SourceVolumeSnapshotAnnotation: "golden",
+		}
+
+		dataVolume, err := createResources(runner.DiskRestoreClone)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dataVolume.Spec.Source).To(Equal(&v1beta1.DataVolumeSource{
+			PVC: &v1beta1.DataVolumeSourcePVC{Namespace: k8sv1.NamespaceDefault, Name: "golden-disk"},
+		}))
+	})
+
+	It("restores the disks from the virtual machine snapshot of the template", func() {
+		template.Annotations = map[string]string{runner.SourceVMSnapshotAnnotation: "golden-vm"}
+		vmSnapshot("golden-vm", true)
+
+		dataVolume, err := createResources(runner.DiskRestoreSnapshot)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dataVolume.Spec.Source.Snapshot).To(Equal(&v1beta1.DataVolumeSourceSnapshot{
+			Namespace: k8sv1.NamespaceDefault,
+			Name:      "vmsnapshot-golden-disk",
+		}))
+	})
+
+	It("keeps the template source with the import strategy", func() {
+		template.Annotations = map[string]string{runner.SourceVMSnapshotAnnotation: "golden-vm"}
+
+		dataVolume, err := createResources(runner.DiskRestoreImport)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dataVolume.Spec.Source.HTTP).NotTo(BeNil())
+		Expect(dataVolume.Spec.Source.Snapshot).To(BeNil())
+	})
+
+	It("fails without creating resources when the snapshot isn't ready", func() {
+		template.Annotations = map[string]string{runner.SourceVMSnapshotAnnotation: "golden-vm"}
+		vmSnapshot("golden-vm", false)
+
+		_, err := createResources(runner.DiskRestoreSnapshot)
+
+		Expect(err).To(MatchError(runner.ErrSnapshotNotReady))
+		Expect(runner.HasAppContext()).To(BeFalse())
+	})
+})
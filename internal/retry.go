@@ -0,0 +1,183 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultRetryMaxAttempts is the number of attempts made by default for
+	// the Kubernetes API calls that fail with a transient error.
+	DefaultRetryMaxAttempts = 5
+	// DefaultRetryInitialBackoff is the default delay before the first retry.
+	DefaultRetryInitialBackoff = 500 * time.Millisecond
+	// DefaultRetryMaxBackoff is the default upper bound of the retry delay.
+	DefaultRetryMaxBackoff = 10 * time.Second
+
+	retryJitter = 0.2
+)
+
+// RetryPolicy controls how the Kubernetes API calls that fail with a
+// transient error are retried. Delays double after each attempt, up to
+// MaxBackoff, with a random jitter of ±20%.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts; 1 disables the retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff bounds the delay between two attempts.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used unless WithRetryPolicy is set.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    DefaultRetryMaxAttempts,
+		InitialBackoff: DefaultRetryInitialBackoff,
+		MaxBackoff:     DefaultRetryMaxBackoff,
+	}
+}
+
+// backoff returns the delay before the given retry, honouring the delay
+// suggested by the API server when there is one.
+func (p RetryPolicy) backoff(retry int, err error) time.Duration {
+	if seconds, ok := k8serrors.SuggestsClientDelay(err); ok && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	delay := p.InitialBackoff
+	for range retry - 1 {
+		delay *= 2
+		if delay >= p.MaxBackoff {
+			delay = p.MaxBackoff
+
+			break
+		}
+	}
+
+	jitter := 1 + retryJitter*(2*rand.Float64()-1) //nolint:gosec // the jitter doesn't need a secure source
+
+	return time.Duration(float64(delay) * jitter)
+}
+
+// isTransientError reports whether a Kubernetes API or OCI registry error is
+// likely caused by a temporary condition of the server.
+func isTransientError(err error) bool {
+	return k8serrors.IsTooManyRequests(err) ||
+		k8serrors.IsServerTimeout(err) ||
+		k8serrors.IsTimeout(err) ||
+		k8serrors.IsInternalError(err) ||
+		k8serrors.IsServiceUnavailable(err) ||
+		k8serrors.IsUnexpectedServerError(err) ||
+		isTransientRegistryError(err)
+}
+
+// withRetry calls fn until it succeeds, fails with a non-transient error or
+// the policy runs out of attempts. Each retry is recorded as an event of the
+// span in ctx.
+func withRetry[T any](ctx context.Context, policy RetryPolicy, operation string, fn func() (T, error)) (T, error) {
+	span := trace.SpanFromContext(ctx)
+
+	for attempt := 1; ; attempt++ {
+		result, err := fn()
+		if err == nil || !isTransientError(err) || attempt >= policy.MaxAttempts {
+			return result, err
+		}
+
+		delay := policy.backoff(attempt, err)
+
+		utils.GetLogger().Printf("%s failed with a transient error, retrying in %v: %v\n", operation, delay, err)
+		span.AddEvent("retry", trace.WithAttributes(
+			attribute.String("operation", operation),
+			attribute.Int("attempt", attempt),
+			attribute.String("delay", delay.String()),
+			attribute.String("error", err.Error()),
+		))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return result, err
+		case <-timer.C:
+		}
+	}
+}
+
+// createWithRetry creates object through withRetry. A Create that timed out
+// may have been stored anyway, so a retry failing with AlreadyExists is
+// resolved by getting the stored object, which is returned when it belongs
+// to the same runner as object. An AlreadyExists on the first attempt is
+// returned as is.
+func createWithRetry[T k8smetav1.Object](
+	ctx context.Context,
+	policy RetryPolicy,
+	operation string,
+	object T,
+	create, get func() (T, error),
+) (T, error) {
+	attempts := 0
+
+	created, err := withRetry(ctx, policy, operation, func() (T, error) {
+		attempts++
+
+		return create()
+	})
+	if attempts == 1 || !k8serrors.IsAlreadyExists(err) {
+		return created, err
+	}
+
+	stored, getErr := get()
+	if getErr != nil {
+		return created, fmt.Errorf("cannot get %s after a retried %s: %w", object.GetName(), operation, getErr)
+	}
+
+	if !sameRunner(object, stored) {
+		return created, fmt.Errorf("%w: %s", ErrResourceConflict, object.GetName())
+	}
+
+	utils.GetLogger().Printf("%s succeeded before being retried, using the stored %s\n", operation, object.GetName())
+
+	return stored, nil
+}
+
+// sameRunner reports whether stored was created for the same runner pod and
+// with the same owners as want.
+func sameRunner(want, stored k8smetav1.Object) bool {
+	if want.GetAnnotations()[RunnerPodAnnotation] != stored.GetAnnotations()[RunnerPodAnnotation] {
+		return false
+	}
+
+	return slices.EqualFunc(want.GetOwnerReferences(), stored.GetOwnerReferences(),
+		func(a, b k8smetav1.OwnerReference) bool {
+			return a.UID == b.UID
+		})
+}
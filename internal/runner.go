@@ -23,6 +23,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
@@ -42,17 +43,14 @@ import (
 
 const (
 	tracerName                   = "kubevirt-actions-runner/runner"
-	runnerInfoAnnotation  string = "electrocucaracha.kubevirt-actions-runner/runner-info"
 	runnerInfoVolume      string = "runner-info"
 	runnerInfoPath        string = "runner-info.json"
 	watchReconnectBackoff        = time.Second
 	watchChannelClosedMsg        = "watch channel closed unexpectedly"
 )
 
-var errWaitTimeout = errors.New("timeout while waiting for the virtual machine instance")
-
 // marshalJSON is a seam over json.Marshal so tests can force the
-// runner-info annotation encoding failure path in getResources.
+// runner-info secret encoding failure path in getResources.
 //
 //nolint:gochecknoglobals
 var marshalJSON = json.Marshal
@@ -63,47 +61,229 @@ type Runner interface {
 		vmTemplateNamespace string,
 		runnerName string,
 		jitConfig string,
+		templateOptions TemplateOptions,
+		overrides ResourceOverrides,
+		metadata ResourceMetadata,
 	) error
 	WaitForVirtualMachineInstance(ctx context.Context) error
 	DeleteResources(ctx context.Context) error
 }
 
 type KubevirtRunner struct {
-	virtClient  kubecli.KubevirtClient
-	namespace   string
-	waitTimeout time.Duration
+	virtClient       kubecli.KubevirtClient
+	namespace        string
+	waitTimeout      time.Duration
+	phaseTimeouts    PhaseTimeouts
+	resourceLimits   ResourceLimits
+	events           *PodEventRecorder
+	runnerPod        *k8scorev1.Pod
+	podOwner         bool
+	deletionTimeout  time.Duration
+	forceDeleteAfter time.Duration
+	retryPolicy      RetryPolicy
+	diagnosticsDir   string
+	serialConsole    bool
+	virtualMachine   bool
+	dataVolumesFirst bool
+	registryClient   *http.Client
+
+	shutdownGracePeriod time.Duration
+	// waitDeadline is shared by the wait for the DataVolumes created first
+	// and WaitForVirtualMachineInstance, so together they never exceed the
+	// wait timeout.
+	waitDeadline time.Time
 }
 
 var _ Runner = (*KubevirtRunner)(nil)
 
-func NewRunner(namespace string, virtClient kubecli.KubevirtClient, waitTimeout time.Duration) *KubevirtRunner {
-	return &KubevirtRunner{
+// Option configures optional KubevirtRunner behavior.
+type Option func(*KubevirtRunner)
+
+// WithSerialConsole enables copying the VMI serial console into the logger
+// while waiting for the runner to complete.
+func WithSerialConsole() Option {
+	return func(rc *KubevirtRunner) {
+		rc.serialConsole = true
+	}
+}
+
+// WithPhaseTimeouts bounds how long the VMI may take to reach each lifecycle
+// milestone while waiting for the runner to complete.
+func WithPhaseTimeouts(timeouts PhaseTimeouts) Option {
+	return func(rc *KubevirtRunner) {
+		rc.phaseTimeouts = timeouts
+	}
+}
+
+// WithVirtualMachine clones the template into a VirtualMachine with the Once
+// run strategy instead of a bare VMI, leaving the DataVolume lifecycle to
+// KubeVirt.
+func WithVirtualMachine() Option {
+	return func(rc *KubevirtRunner) {
+		rc.virtualMachine = true
+	}
+}
+
+// WithResourceLimits bounds the CPU, memory and disk size overrides accepted
+// by CreateResources.
+func WithResourceLimits(limits ResourceLimits) Option {
+	return func(rc *KubevirtRunner) {
+		rc.resourceLimits = limits
+	}
+}
+
+// WithEventRecorder records the runner lifecycle as Kubernetes Events on the
+// pod running kar.
+func WithEventRecorder(recorder *PodEventRecorder) Option {
+	return func(rc *KubevirtRunner) {
+		rc.events = recorder
+	}
+}
+
+// WithRunnerPod identifies the pod running kar. Its name is recorded on the
+// runner resources so orphans can be found once the pod is gone.
+func WithRunnerPod(pod *k8scorev1.Pod) Option {
+	return func(rc *KubevirtRunner) {
+		rc.runnerPod = pod
+	}
+}
+
+// WithPodOwner makes the runner pod the owner of the VMI, or of the
+// VirtualMachine, so Kubernetes garbage-collects the runner resources when
+// the pod is deleted without running the cleanup. It requires WithRunnerPod.
+func WithPodOwner() Option {
+	return func(rc *KubevirtRunner) {
+		rc.podOwner = true
+	}
+}
+
+// WithWaitForDeletion makes DeleteResources wait up to timeout until the
+// runner VMI, or VirtualMachine, and its DataVolumes are gone. Deletions that
+// are still pending after forceAfter are retried with a zero grace period; a
+// zero forceAfter escalates at half of the timeout.
+func WithWaitForDeletion(timeout, forceAfter time.Duration) Option {
+	if forceAfter <= 0 {
+		forceAfter = timeout / 2 //nolint:mnd // half of the cleanup window
+	}
+
+	return func(rc *KubevirtRunner) {
+		rc.deletionTimeout = timeout
+		rc.forceDeleteAfter = forceAfter
+	}
+}
+
+// WithRetryPolicy overrides how the Kubernetes API calls that fail with a
+// transient error are retried.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(rc *KubevirtRunner) {
+		rc.retryPolicy = policy
+	}
+}
+
+func NewRunner(
+	namespace string,
+	virtClient kubecli.KubevirtClient,
+	waitTimeout time.Duration,
+	opts ...Option,
+) *KubevirtRunner {
+	runner := &KubevirtRunner{
 		namespace:   namespace,
 		virtClient:  virtClient,
 		waitTimeout: waitTimeout,
+		retryPolicy: DefaultRetryPolicy(),
+	}
+
+	for _, opt := range opts {
+		opt(runner)
 	}
+
+	return runner
 }
 
-func generateRunnerInfoVolume() v1.Volume {
+func generateRunnerInfoVolume(secretName string) v1.Volume {
 	return v1.Volume{
 		Name: runnerInfoVolume,
 		VolumeSource: v1.VolumeSource{
-			DownwardAPI: &v1.DownwardAPIVolumeSource{
-				Fields: []k8scorev1.DownwardAPIVolumeFile{
-					{
-						Path: runnerInfoPath,
-						FieldRef: &k8scorev1.ObjectFieldSelector{
-							FieldPath: fmt.Sprintf("metadata.annotations['%s']", runnerInfoAnnotation),
-						},
-					},
-				},
+			Secret: &v1.SecretVolumeSource{
+				SecretName: secretName,
 			},
 		},
 	}
 }
 
+// generateRunnerInfoSecret builds the Secret that carries the runner-info
+// payload, keeping the JIT config out of the VMI object itself.
+func generateRunnerInfoSecret(runnerName string, payload []byte) *k8scorev1.Secret {
+	return &k8scorev1.Secret{
+		ObjectMeta: k8smetav1.ObjectMeta{
+			Name: fmt.Sprintf("%s-%s", runnerInfoVolume, runnerName),
+		},
+		Type: k8scorev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			runnerInfoPath: payload,
+		},
+	}
+}
+
+// newRunnerInfoSecret encodes the JIT config into the runner-info Secret.
+func newRunnerInfoSecret(runnerName, jitConfig string) (*k8scorev1.Secret, error) {
+	runnerInfo := map[string]string{
+		"jitconfig": jitConfig,
+	}
+
+	out, err := marshalJSON(runnerInfo)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal runner info secret payload: %w", err)
+	}
+
+	return generateRunnerInfoSecret(runnerName, out), nil
+}
+
+// podOwnerReference returns the reference to the runner pod configured with
+// WithPodOwner. Owners must live in the namespace of their dependents, so no
+// reference is returned for a pod of another namespace.
+func (rc *KubevirtRunner) podOwnerReference() []k8smetav1.OwnerReference {
+	if !rc.podOwner || rc.runnerPod == nil {
+		return nil
+	}
+
+	if rc.runnerPod.Namespace != rc.namespace {
+		utils.GetLogger().Printf("Runner pod %s/%s is outside of the %s namespace; skipping its owner reference\n",
+			rc.runnerPod.Namespace, rc.runnerPod.Name, rc.namespace)
+
+		return nil
+	}
+
+	return []k8smetav1.OwnerReference{
+		{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Name:       rc.runnerPod.Name,
+			UID:        rc.runnerPod.UID,
+		},
+	}
+}
+
+// ownerReference returns the reference used to tie runner resources to the
+// lifecycle of the given KubeVirt object so Kubernetes garbage-collects them
+// with it.
+func ownerReference(kind, name string, uid types.UID) []k8smetav1.OwnerReference {
+	return []k8smetav1.OwnerReference{
+		{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       kind,
+			Name:       name,
+			UID:        uid,
+			Controller: new(bool),
+		},
+	}
+}
+
 func (rc *KubevirtRunner) CreateResources(ctx context.Context,
 	vmTemplate, vmTemplateNamespace, runnerName, jitConfig string,
+	templateOptions TemplateOptions,
+	overrides ResourceOverrides,
+	metadata ResourceMetadata,
 ) error {
 	tracer := otel.Tracer(tracerName)
 
@@ -126,12 +306,36 @@ func (rc *KubevirtRunner) CreateResources(ctx context.Context,
 		return err
 	}
 
-	virtualMachineInstance, dataVolume, err := rc.getResources(
+	err = rc.resourceLimits.validate(overrides)
+	if err != nil {
+		span.RecordError(err)
+
+		return err
+	}
+
+	err = metadata.validate()
+	if err != nil {
+		span.RecordError(err)
+
+		return err
+	}
+
+	management := rc.managementMetadata(vmTemplate, vmTemplateNamespace, runnerName, metadata)
+
+	if rc.virtualMachine {
+		return rc.createVirtualMachineResources(ctx, tracer, span,
+			vmTemplate, vmTemplateNamespace, runnerName, jitConfig, templateOptions, overrides, management)
+	}
+
+	virtualMachineInstance, dataVolumes, secret, err := rc.getResources(
 		ctx,
 		vmTemplate,
 		vmTemplateNamespace,
 		runnerName,
 		jitConfig,
+		templateOptions,
+		overrides,
+		management,
 	)
 	if err != nil {
 		span.RecordError(err)
@@ -139,6 +343,39 @@ func (rc *KubevirtRunner) CreateResources(ctx context.Context,
 		return err
 	}
 
+	waitDeadline := time.Now().Add(rc.waitTimeout)
+
+	dataVolumeNames, err := rc.createInstanceResources(ctx, tracer, span,
+		virtualMachineInstance, dataVolumes, secret, waitDeadline)
+	if err != nil {
+		return err
+	}
+
+	if rc.dataVolumesFirst && len(dataVolumeNames) > 0 {
+		rc.waitDeadline = waitDeadline
+	}
+
+	NewAppContext(virtualMachineInstance.Name, dataVolumeNames, secret.Name)
+
+	return nil
+}
+
+// createInstanceResources creates the VMI, then its runner-info Secret and
+// DataVolumes owned by it, and returns the names of the DataVolumes. When the
+// DataVolumes are created first, they are awaited until waitDeadline.
+func (rc *KubevirtRunner) createInstanceResources(
+	ctx context.Context,
+	tracer trace.Tracer,
+	span trace.Span,
+	virtualMachineInstance *v1.VirtualMachineInstance,
+	dataVolumes []*v1beta1.DataVolume,
+	secret *k8scorev1.Secret,
+	waitDeadline time.Time,
+) ([]string, error) {
+	if rc.dataVolumesFirst && len(dataVolumes) > 0 {
+		return rc.createDataVolumesFirst(ctx, tracer, span, virtualMachineInstance, dataVolumes, secret, waitDeadline)
+	}
+
 	_, spanCreateVMI := tracer.Start(ctx, "CreateVMI",
 		trace.WithAttributes(
 			attribute.String("vmiName", virtualMachineInstance.Name),
@@ -148,25 +385,39 @@ func (rc *KubevirtRunner) CreateResources(ctx context.Context,
 
 	vmi, err := rc.createVMI(ctx, virtualMachineInstance, span, spanCreateVMI)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	dataVolumeName, err := rc.createOptionalDataVolume(ctx, tracer, dataVolume, vmi, span)
+	owner := ownerReference(v1.VirtualMachineInstanceGroupVersionKind.Kind, vmi.Name, vmi.UID)
+
+	err = rc.createSecret(ctx, tracer, secret, owner, span)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	NewAppContext(virtualMachineInstance.Name, dataVolumeName)
+	return rc.createDataVolumes(ctx, tracer, dataVolumes, owner, span)
+}
 
-	return nil
+// waitContext bounds the wait for the VMI by the deadline already shared
+// with the DataVolumes, or by the wait timeout.
+func (rc *KubevirtRunner) waitContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if !rc.waitDeadline.IsZero() {
+		return context.WithDeadline(ctx, rc.waitDeadline)
+	}
+
+	return context.WithTimeout(ctx, rc.waitTimeout)
 }
 
 func (rc *KubevirtRunner) WaitForVirtualMachineInstance(ctx context.Context) error {
 	tracer := otel.Tracer(tracerName)
+	parentCtx := ctx
 
-	ctx, cancel := context.WithTimeout(ctx, rc.waitTimeout)
+	ctx, cancel := rc.waitContext(ctx)
 	defer cancel()
 
+	ctx, cancelCause := context.WithCancelCause(ctx)
+	defer cancelCause(nil)
+
 	ctx, span := tracer.Start(ctx, "WaitForVirtualMachineInstance")
 	defer span.End()
 
@@ -176,28 +427,73 @@ func (rc *KubevirtRunner) WaitForVirtualMachineInstance(ctx context.Context) err
 	log.Printf("Watching %s Virtual Machine Instance\n", vmiName)
 	span.SetAttributes(attribute.String("vmiName", vmiName))
 
-	var (
-		currentStatus v1.VirtualMachineInstancePhase
-		readyReported bool
-	)
+	state := &vmiWatchState{
+		deadlines: armPhaseDeadlines(rc.phaseTimeouts, cancelCause),
+		events:    rc.events,
+	}
+	defer state.deadlines.stop()
 
 	vmiInterface := rc.virtClient.VirtualMachineInstance(rc.namespace)
 
+	if rc.serialConsole {
+		consoleCtx, stopConsole := context.WithCancel(ctx)
+		defer stopConsole()
+
+		go streamSerialConsole(consoleCtx, span, vmiInterface, vmiName)
+	}
+
+	if dataVolumeNames := GetAppContext().GetDataVolumeNames(); len(dataVolumeNames) > 0 {
+		monitorCtx, stopMonitor := context.WithCancel(ctx)
+		defer stopMonitor()
+
+		go rc.monitorDataVolumes(monitorCtx, span, dataVolumeNames, cancelCause)
+	}
+
+	err := rc.watchUntilTerminal(ctx, span, vmiInterface, vmiName, state)
+	if isWaitTimeout(err) {
+		rc.events.Warningf(EventReasonTimeout, "Virtual machine instance %s: %v", vmiName, err)
+	}
+
+	// The wait context is done on timeouts, so the failure is diagnosed with
+	// the parent one, unless the run itself was interrupted.
+	if parentCtx.Err() == nil &&
+		(errors.Is(err, ErrRunnerFailed) || errors.Is(err, ErrDataVolumeFailed) || isWaitTimeout(err)) {
+		err = rc.diagnoseFailure(parentCtx, vmiName, err)
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+// watchUntilTerminal follows the VMI, re-establishing the watch whenever
+// it closes, until a terminal phase is reached or ctx is done.
+func (rc *KubevirtRunner) watchUntilTerminal(
+	ctx context.Context,
+	span trace.Span,
+	vmiInterface kubecli.VirtualMachineInstanceInterface,
+	vmiName string,
+	state *vmiWatchState,
+) error {
+	log := utils.GetLogger()
+
 	for {
 		done, resourceVersion, terminalErr := rc.refreshVMIStatus(
-			ctx, span, vmiInterface, vmiName, &currentStatus, &readyReported)
+			ctx, span, vmiInterface, vmiName, state)
 		if done {
 			return terminalErr
 		}
 
-		watch, watchErr := vmiInterface.Watch(ctx, watchOptions(vmiName, resourceVersion))
+		watch, watchErr := withRetry(ctx, rc.retryPolicy, "watch virtual machine instance",
+			func() (k8swatch.Interface, error) {
+				return vmiInterface.Watch(ctx, watchOptions(vmiName, resourceVersion))
+			})
 		if watchErr != nil {
 			span.RecordError(watchErr)
 
 			return fmt.Errorf("failed to watch the virtual machine instance: %w", watchErr)
 		}
 
-		done, watchResultErr := watchVMIEvents(ctx, span, watch, vmiName, &currentStatus, &readyReported)
+		done, watchResultErr := watchVMIEvents(ctx, span, watch, vmiName, state)
 		watch.Stop()
 
 		if done {
@@ -214,7 +510,7 @@ func (rc *KubevirtRunner) WaitForVirtualMachineInstance(ctx context.Context) err
 		case <-ctx.Done():
 			timer.Stop()
 
-			return errWaitTimeout
+			return waitTimeoutCause(ctx)
 		case <-timer.C:
 		}
 	}
@@ -232,19 +528,18 @@ func watchVMIEvents(
 	span trace.Span,
 	watch k8swatch.Interface,
 	vmiName string,
-	currentStatus *v1.VirtualMachineInstancePhase,
-	readyReported *bool,
+	state *vmiWatchState,
 ) (bool, error) {
 	for {
 		select {
 		case <-ctx.Done():
-			return true, errWaitTimeout
+			return true, waitTimeoutCause(ctx)
 		case event, watchOpen := <-watch.ResultChan():
 			if !watchOpen {
 				return false, nil
 			}
 
-			done, skip, err := handleWatchEvent(span, vmiName, event, currentStatus, readyReported)
+			done, skip, err := handleWatchEvent(span, vmiName, event, state)
 			if skip {
 				continue
 			}
@@ -264,19 +559,28 @@ func handleWatchEvent(
 	span trace.Span,
 	vmiName string,
 	event k8swatch.Event,
-	currentStatus *v1.VirtualMachineInstancePhase,
-	readyReported *bool,
+	state *vmiWatchState,
 ) (bool, bool, error) {
 	vmi, isVMI := event.Object.(*v1.VirtualMachineInstance)
 	if !isVMI || vmi.Name != vmiName {
 		return false, true, nil
 	}
 
-	done, err := evaluateVMIStatus(span, vmiName, vmi, currentStatus, readyReported)
+	done, err := evaluateVMIStatus(span, vmiName, vmi, state)
 
 	return done, false, err
 }
 
+// vmiWatchState carries what has been observed about the VMI across watch
+// reconnects.
+type vmiWatchState struct {
+	currentStatus v1.VirtualMachineInstancePhase
+	observed      bool
+	readyReported bool
+	deadlines     *phaseDeadlines
+	events        *PodEventRecorder
+}
+
 // evaluateVMIStatus reports the readiness milestone and processes a phase
 // transition for a VMI observation. It returns done=true when a terminal
 // phase (Succeeded or Failed) has been reached.
@@ -284,35 +588,51 @@ func evaluateVMIStatus(
 	span trace.Span,
 	vmiName string,
 	vmi *v1.VirtualMachineInstance,
-	currentStatus *v1.VirtualMachineInstancePhase,
-	readyReported *bool,
+	state *vmiWatchState,
 ) (bool, error) {
-	reportReadyMilestone(span, vmiName, vmi, readyReported)
+	state.observed = true
+
+	wasReady := state.readyReported
+	reportReadyMilestone(span, vmiName, vmi, &state.readyReported)
 
-	if vmi.Status.Phase == *currentStatus {
+	if !wasReady && state.readyReported {
+		state.events.Normalf(EventReasonReady, "Virtual machine instance %s is Running and Ready", vmiName)
+	}
+
+	state.deadlines.observe(vmi.Status.Phase, state.readyReported)
+	state.deadlines.observeScheduling(isVMIUnschedulable(vmi))
+
+	if vmi.Status.Phase == state.currentStatus {
 		return false, nil
 	}
 
-	done, err := handleVMIPhase(span, vmiName, vmi.Status.Phase)
-	*currentStatus = vmi.Status.Phase
+	done, err := handleVMIPhase(span, state.events, vmiName, vmi.Status.Phase)
+	state.currentStatus = vmi.Status.Phase
 
 	return done, err
 }
 
 // handleVMIPhase processes a VMI phase transition. It returns (true, err) when a
 // terminal state (Succeeded or Failed) is reached, or (false, nil) for non-terminal phases.
-func handleVMIPhase(span trace.Span, vmiName string, phase v1.VirtualMachineInstancePhase) (bool, error) {
+func handleVMIPhase(
+	span trace.Span,
+	events *PodEventRecorder,
+	vmiName string,
+	phase v1.VirtualMachineInstancePhase,
+) (bool, error) {
 	log := utils.GetLogger()
 
 	switch phase {
 	case v1.Succeeded:
 		log.Printf("%s has successfully completed\n", vmiName)
 		span.SetAttributes(attribute.String("phase", "Succeeded"))
+		events.Normalf(EventReasonSucceeded, "Virtual machine instance %s has successfully completed", vmiName)
 
 		return true, nil
 	case v1.Failed:
 		log.Printf("%s has failed\n", vmiName)
 		span.SetAttributes(attribute.String("phase", "Failed"))
+		events.Warningf(EventReasonFailed, "Virtual machine instance %s has failed", vmiName)
 
 		return true, ErrRunnerFailed
 	case v1.VmPhaseUnset, v1.Pending, v1.Scheduling, v1.Scheduled, v1.Running, v1.Unknown, v1.WaitingForSync:
@@ -320,6 +640,8 @@ func handleVMIPhase(span trace.Span, vmiName string, phase v1.VirtualMachineInst
 		span.AddEvent("phase_transition", trace.WithAttributes(
 			attribute.String("phase", string(phase)),
 		))
+		events.Normalf(EventReasonPhaseTransition, "Virtual machine instance %s has transitioned to %s phase",
+			vmiName, phase)
 
 		return false, nil
 	default:
@@ -371,27 +693,96 @@ func (rc *KubevirtRunner) DeleteResources(ctx context.Context) error {
 		appCtx.GetVMIName())
 	span.SetAttributes(attribute.String("vmiName", appCtx.GetVMIName()))
 
-	err := rc.virtClient.VirtualMachineInstance(rc.namespace).Delete(
-		ctx, appCtx.GetVMIName(), k8smetav1.DeleteOptions{})
-	logDeleteErr(log, span, "runner instance", appCtx.GetVMIName(), err)
+	if rc.virtualMachine {
+		// Deleting the VirtualMachine cascades to its VMI and DataVolumes.
+		_ = rc.deleteVirtualMachine(ctx, span, appCtx.GetVMIName(), k8smetav1.DeleteOptions{})
+	} else {
+		_ = rc.deleteVMI(ctx, span, appCtx.GetVMIName(), k8smetav1.DeleteOptions{})
+	}
 
-	if len(appCtx.GetDataVolumeName()) > 0 {
-		_, spanDeleteDV := tracer.Start(ctx, "DeleteDataVolume",
-			trace.WithAttributes(
-				attribute.String("dataVolumeName", appCtx.GetDataVolumeName()),
-			),
-		)
+	for _, dataVolumeName := range appCtx.GetDataVolumeNames() {
+		_ = rc.deleteDataVolume(ctx, tracer, dataVolumeName, k8smetav1.DeleteOptions{})
+	}
 
-		err := rc.virtClient.CdiClient().CdiV1beta1().DataVolumes(rc.namespace).Delete(
-			ctx, appCtx.GetDataVolumeName(), k8smetav1.DeleteOptions{})
-		logDeleteErr(log, spanDeleteDV, "runner data volume", appCtx.GetDataVolumeName(), err)
+	if len(appCtx.GetSecretName()) > 0 {
+		_ = rc.deleteSecret(ctx, tracer, appCtx.GetSecretName())
+	}
 
-		spanDeleteDV.End()
+	if rc.deletionTimeout <= 0 {
+		return nil
+	}
+
+	err := rc.awaitDeletion(ctx, span, rc.deletionTargets(tracer, span, appCtx))
+	if err != nil {
+		span.RecordError(err)
+
+		return err
 	}
 
 	return nil
 }
 
+func (rc *KubevirtRunner) deleteVirtualMachine(
+	ctx context.Context,
+	span trace.Span,
+	name string,
+	options k8smetav1.DeleteOptions,
+) error {
+	err := rc.virtClient.VirtualMachine(rc.namespace).Delete(ctx, name, options)
+	logDeleteErr(utils.GetLogger(), span, "runner virtual machine", name, err)
+	rc.recordDeletion("virtual machine", name, err)
+
+	return err
+}
+
+func (rc *KubevirtRunner) deleteVMI(
+	ctx context.Context,
+	span trace.Span,
+	name string,
+	options k8smetav1.DeleteOptions,
+) error {
+	err := rc.virtClient.VirtualMachineInstance(rc.namespace).Delete(ctx, name, options)
+	logDeleteErr(utils.GetLogger(), span, "runner instance", name, err)
+	rc.recordDeletion("virtual machine instance", name, err)
+
+	return err
+}
+
+func (rc *KubevirtRunner) deleteDataVolume(
+	ctx context.Context,
+	tracer trace.Tracer,
+	name string,
+	options k8smetav1.DeleteOptions,
+) error {
+	_, spanDeleteDV := tracer.Start(ctx, "DeleteDataVolume",
+		trace.WithAttributes(
+			attribute.String("dataVolumeName", name),
+		),
+	)
+	defer spanDeleteDV.End()
+
+	err := rc.virtClient.CdiClient().CdiV1beta1().DataVolumes(rc.namespace).Delete(ctx, name, options)
+	logDeleteErr(utils.GetLogger(), spanDeleteDV, "runner data volume", name, err)
+	rc.recordDeletion("data volume", name, err)
+
+	return err
+}
+
+func (rc *KubevirtRunner) deleteSecret(ctx context.Context, tracer trace.Tracer, name string) error {
+	_, spanDeleteSecret := tracer.Start(ctx, "DeleteSecret",
+		trace.WithAttributes(
+			attribute.String("secretName", name),
+		),
+	)
+	defer spanDeleteSecret.End()
+
+	err := rc.virtClient.CoreV1().Secrets(rc.namespace).Delete(ctx, name, k8smetav1.DeleteOptions{})
+	logDeleteErr(utils.GetLogger(), spanDeleteSecret, "runner info secret", name, err)
+	rc.recordDeletion("secret", name, err)
+
+	return err
+}
+
 // logDeleteErr logs and records a deletion error unless it indicates the
 // resource was already gone, in which case it is silently ignored.
 func logDeleteErr(log *utils.LoggerImpl, span trace.Span, resourceKind, name string, err error) {
@@ -403,22 +794,43 @@ func logDeleteErr(log *utils.LoggerImpl, span trace.Span, resourceKind, name str
 	span.RecordError(err)
 }
 
+// recordDeletion reports the outcome of a deletion as an event, skipping
+// resources that were already gone.
+func (rc *KubevirtRunner) recordDeletion(resourceKind, name string, err error) {
+	switch {
+	case err == nil:
+		rc.events.Normalf(EventReasonDeleted, "Deleted %s %s", resourceKind, name)
+	case !k8serrors.IsNotFound(err):
+		rc.events.Warningf(EventReasonFailedDelete, "Failed to delete %s %s: %v", resourceKind, name, err)
+	}
+}
+
 func (rc *KubevirtRunner) refreshVMIStatus(
 	ctx context.Context,
 	span trace.Span,
 	vmiInterface kubecli.VirtualMachineInstanceInterface,
 	vmiName string,
-	currentStatus *v1.VirtualMachineInstancePhase,
-	readyReported *bool,
+	state *vmiWatchState,
 ) (bool, string, error) {
 	if ctx.Err() != nil {
-		return true, "", errWaitTimeout
+		return true, "", waitTimeoutCause(ctx)
 	}
 
-	vmi, err := vmiInterface.Get(ctx, vmiName, k8smetav1.GetOptions{})
+	vmi, err := withRetry(ctx, rc.retryPolicy, "get virtual machine instance",
+		func() (*v1.VirtualMachineInstance, error) {
+			return vmiInterface.Get(ctx, vmiName, k8smetav1.GetOptions{})
+		})
 	if err != nil {
 		if ctx.Err() != nil {
-			return true, "", errWaitTimeout
+			return true, "", waitTimeoutCause(ctx)
+		}
+
+		// The VirtualMachine controller creates the VMI asynchronously, so
+		// its absence is expected until it has been observed once.
+		if rc.virtualMachine && !state.observed && k8serrors.IsNotFound(err) {
+			span.AddEvent("vmi_pending_creation")
+
+			return false, "", nil
 		}
 
 		span.RecordError(err)
@@ -426,7 +838,7 @@ func (rc *KubevirtRunner) refreshVMIStatus(
 		return true, "", fmt.Errorf("failed to get the virtual machine instance %q: %w", vmiName, err)
 	}
 
-	done, err := evaluateVMIStatus(span, vmiName, vmi, currentStatus, readyReported)
+	done, err := evaluateVMIStatus(span, vmiName, vmi, state)
 
 	return done, vmi.ResourceVersion, err
 }
@@ -461,18 +873,38 @@ func (rc *KubevirtRunner) createVMI(
 	log := utils.GetLogger()
 	log.Printf("Creating %s Virtual Machine Instance\n", vmi.Name)
 
-	createdVMI, err := rc.virtClient.VirtualMachineInstance(rc.namespace).Create(ctx,
-		vmi, k8smetav1.CreateOptions{})
+	vmi.OwnerReferences = rc.podOwnerReference()
+
+	vmiInterface := rc.virtClient.VirtualMachineInstance(rc.namespace)
+
+	createdVMI, err := createWithRetry(ctx, rc.retryPolicy, "create virtual machine instance", vmi,
+		func() (*v1.VirtualMachineInstance, error) {
+			return vmiInterface.Create(ctx, vmi, k8smetav1.CreateOptions{})
+		},
+		func() (*v1.VirtualMachineInstance, error) {
+			return vmiInterface.Get(ctx, vmi.Name, k8smetav1.GetOptions{})
+		})
 	if err != nil {
 		if k8serrors.IsAlreadyExists(err) {
 			log.Printf("Virtual Machine Instance %s already exists\n", vmi.Name)
 			span.SetAttributes(attribute.String("vmiName", vmi.Name))
 			spanCreateVMI.SetAttributes(attribute.String("vmiName", vmi.Name))
 
-			return createdVMI, nil
+			// The existing VMI is fetched so dependent resources can still
+			// reference its UID as their owner.
+			existingVMI, getErr := vmiInterface.Get(ctx, vmi.Name, k8smetav1.GetOptions{})
+			if getErr != nil {
+				spanCreateVMI.RecordError(getErr)
+				span.RecordError(getErr)
+
+				return nil, fmt.Errorf("failed to get existing runner instance: %w", getErr)
+			}
+
+			return existingVMI, nil
 		}
 
 		log.Printf("Failed to create runner instance %s: %v\n", vmi.Name, err)
+		rc.events.Warningf(EventReasonFailedCreate, "Failed to create virtual machine instance %s: %v", vmi.Name, err)
 		span.SetAttributes(attribute.String("error", err.Error()))
 		spanCreateVMI.SetAttributes(attribute.String("error", err.Error()))
 		spanCreateVMI.RecordError(err)
@@ -481,6 +913,8 @@ func (rc *KubevirtRunner) createVMI(
 		return nil, fmt.Errorf("failed to create runner instance: %w", err)
 	}
 
+	rc.events.Normalf(EventReasonCreated, "Created virtual machine instance %s", vmi.Name)
+
 	return createdVMI, nil
 }
 
@@ -488,8 +922,7 @@ func (rc *KubevirtRunner) createDataVolume(
 	ctx context.Context,
 	tracer trace.Tracer,
 	dataVolume *v1beta1.DataVolume,
-	vmiName string,
-	vmiUID types.UID,
+	owner []k8smetav1.OwnerReference,
 	span trace.Span,
 ) error {
 	log := utils.GetLogger()
@@ -502,102 +935,231 @@ func (rc *KubevirtRunner) createDataVolume(
 	)
 	defer spanCreateDV.End()
 
-	dataVolume.OwnerReferences = []k8smetav1.OwnerReference{
-		{
-			APIVersion: "kubevirt.io/v1",
-			Kind:       "VirtualMachineInstance",
-			Name:       vmiName,
-			UID:        vmiUID,
-			Controller: new(bool),
-		},
-	}
+	dataVolume.OwnerReferences = owner
 
-	_, err := rc.virtClient.CdiClient().CdiV1beta1().DataVolumes(
-		rc.namespace).Create(ctx, dataVolume, k8smetav1.CreateOptions{})
+	dataVolumes := rc.virtClient.CdiClient().CdiV1beta1().DataVolumes(rc.namespace)
+
+	_, err := createWithRetry(ctx, rc.retryPolicy, "create data volume", dataVolume,
+		func() (*v1beta1.DataVolume, error) {
+			return dataVolumes.Create(ctx, dataVolume, k8smetav1.CreateOptions{})
+		},
+		func() (*v1beta1.DataVolume, error) {
+			return dataVolumes.Get(ctx, dataVolume.Name, k8smetav1.GetOptions{})
+		})
 	if err != nil {
 		spanCreateDV.RecordError(err)
 		span.RecordError(err)
+		rc.events.Warningf(EventReasonFailedCreate, "Failed to create data volume %s: %v", dataVolume.Name, err)
 
 		return fmt.Errorf("cannot create data volume: %w", err)
 	}
 
+	rc.events.Normalf(EventReasonCreated, "Created data volume %s", dataVolume.Name)
+
 	return nil
 }
 
-func (rc *KubevirtRunner) createOptionalDataVolume(
+func (rc *KubevirtRunner) createSecret(
 	ctx context.Context,
 	tracer trace.Tracer,
-	dataVolume *v1beta1.DataVolume,
-	vmi *v1.VirtualMachineInstance,
+	secret *k8scorev1.Secret,
+	owner []k8smetav1.OwnerReference,
 	span trace.Span,
-) (string, error) {
-	if dataVolume == nil {
-		return "", nil
+) error {
+	log := utils.GetLogger()
+	log.Printf("Creating %s Secret\n", secret.Name)
+
+	_, spanCreateSecret := tracer.Start(ctx, "CreateSecret",
+		trace.WithAttributes(
+			attribute.String("secretName", secret.Name),
+		),
+	)
+	defer spanCreateSecret.End()
+
+	secret.OwnerReferences = owner
+
+	// A retried Create that fails with AlreadyExists is resolved by the
+	// replacement too, whether the Secret was stored by the timed out attempt
+	// or left behind by a previous runner.
+	_, err := withRetry(ctx, rc.retryPolicy, "create secret", func() (*k8scorev1.Secret, error) {
+		return rc.virtClient.CoreV1().Secrets(rc.namespace).Create(ctx, secret, k8smetav1.CreateOptions{})
+	})
+	if k8serrors.IsAlreadyExists(err) {
+		log.Printf("Secret %s already exists, replacing its content\n", secret.Name)
+
+		err = rc.replaceSecret(ctx, secret)
 	}
 
-	err := rc.createDataVolume(ctx, tracer, dataVolume, vmi.Name, vmi.UID, span)
 	if err != nil {
-		return "", err
+		spanCreateSecret.RecordError(err)
+		span.RecordError(err)
+		rc.events.Warningf(EventReasonFailedCreate, "Failed to create secret %s: %v", secret.Name, err)
+
+		return fmt.Errorf("cannot create runner info secret: %w", err)
+	}
+
+	rc.events.Normalf(EventReasonCreated, "Created secret %s", secret.Name)
+
+	return nil
+}
+
+// replaceSecret overwrites a Secret left behind by a previous runner with the
+// same name, so the guest never boots with a stale jit config or owner.
+func (rc *KubevirtRunner) replaceSecret(ctx context.Context, secret *k8scorev1.Secret) error {
+	secrets := rc.virtClient.CoreV1().Secrets(rc.namespace)
+
+	existing, err := withRetry(ctx, rc.retryPolicy, "get secret", func() (*k8scorev1.Secret, error) {
+		return secrets.Get(ctx, secret.Name, k8smetav1.GetOptions{})
+	})
+	if err != nil {
+		return fmt.Errorf("cannot get existing secret: %w", err)
+	}
+
+	existing.Labels = secret.Labels
+	existing.Annotations = secret.Annotations
+	existing.OwnerReferences = secret.OwnerReferences
+	existing.Data = secret.Data
+	existing.StringData = secret.StringData
+
+	_, err = withRetry(ctx, rc.retryPolicy, "update secret", func() (*k8scorev1.Secret, error) {
+		return secrets.Update(ctx, existing, k8smetav1.UpdateOptions{})
+	})
+	if err != nil {
+		return fmt.Errorf("cannot update existing secret: %w", err)
+	}
+
+	return nil
+}
+
+// createDataVolumes creates every DataVolume cloned from the template and
+// returns the names of the ones created, in template order.
+func (rc *KubevirtRunner) createDataVolumes(
+	ctx context.Context,
+	tracer trace.Tracer,
+	dataVolumes []*v1beta1.DataVolume,
+	owner []k8smetav1.OwnerReference,
+	span trace.Span,
+) ([]string, error) {
+	dataVolumeNames := make([]string, 0, len(dataVolumes))
+
+	for _, dataVolume := range dataVolumes {
+		err := rc.createDataVolume(ctx, tracer, dataVolume, owner, span)
+		if err != nil {
+			return nil, err
+		}
+
+		dataVolumeNames = append(dataVolumeNames, dataVolume.Name)
 	}
 
-	return dataVolume.Name, nil
+	return dataVolumeNames, nil
 }
 
 func (rc *KubevirtRunner) getResources(
 	ctx context.Context,
 	vmTemplate, vmTemplateNamespace, runnerName, jitConfig string,
+	templateOptions TemplateOptions,
+	overrides ResourceOverrides,
+	management managementMetadata,
 ) (
-	*v1.VirtualMachineInstance, *v1beta1.DataVolume, error,
+	*v1.VirtualMachineInstance, []*v1beta1.DataVolume, *k8scorev1.Secret, error,
 ) {
-	virtualMachine, err := rc.virtClient.VirtualMachine(vmTemplateNamespace).Get(
-		ctx, vmTemplate, k8smetav1.GetOptions{})
+	virtualMachine, err := rc.getTemplate(ctx, templateOptions.Source, vmTemplate, vmTemplateNamespace)
 	if err != nil {
-		return nil, nil, fmt.Errorf(
-			"failed to get KubeVirt virtual machine template %q in namespace %q: %w",
-			vmTemplate,
-			vmTemplateNamespace,
-			err,
-		)
+		return nil, nil, nil, err
 	}
 
-	virtualMachineInstance := v1.NewVMIReferenceFromNameWithNS(rc.namespace, runnerName)
-	virtualMachineInstance.Spec = virtualMachine.Spec.Template.Spec
-
-	if virtualMachineInstance.Annotations == nil {
-		virtualMachineInstance.Annotations = make(map[string]string)
+	virtualMachine, err = rc.expandTemplate(virtualMachine)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	runnerInfo := map[string]string{
-		"jitconfig": jitConfig,
+	err = rc.restoreDiskSources(ctx, virtualMachine, vmTemplateNamespace, templateOptions.DiskRestoreStrategy)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	out, err := marshalJSON(runnerInfo)
+	overrides.applyToBootDataVolume(&virtualMachine.Spec)
+
+	virtualMachineInstance := v1.NewVMIReferenceFromNameWithNS(rc.namespace, runnerName)
+	virtualMachineInstance.Spec = virtualMachine.Spec.Template.Spec
+	management.apply(&virtualMachineInstance.ObjectMeta)
+	overrides.applyToSpec(&virtualMachineInstance.Spec)
+	rc.applyShutdownGracePeriod(&virtualMachineInstance.Spec)
+
+	secret, err := newRunnerInfoSecret(runnerName, jitConfig)
 	if err != nil {
-		return nil, nil, fmt.Errorf("cannot marshal runner info annotation payload: %w", err)
+		return nil, nil, nil, err
 	}
 
-	virtualMachineInstance.Annotations[runnerInfoAnnotation] = string(out)
+	management.apply(&secret.ObjectMeta)
 
-	var dataVolume *v1beta1.DataVolume
+	var dataVolumes []*v1beta1.DataVolume
 
 	for _, dvt := range virtualMachine.Spec.DataVolumeTemplates {
 		for _, volume := range virtualMachineInstance.Spec.Volumes {
 			if volume.DataVolume != nil && volume.DataVolume.Name == dvt.Name {
-				dataVolume = &v1beta1.DataVolume{
+				dataVolume := &v1beta1.DataVolume{
 					ObjectMeta: k8smetav1.ObjectMeta{
 						Name: fmt.Sprintf("%s-%s", dvt.Name, runnerName),
 					},
 					Spec: dvt.Spec,
 				}
+				management.apply(&dataVolume.ObjectMeta)
 
 				volume.DataVolume.Name = dataVolume.Name
+				dataVolumes = append(dataVolumes, dataVolume)
 
 				break
 			}
 		}
 	}
 
-	virtualMachineInstance.Spec.Volumes = append(virtualMachineInstance.Spec.Volumes, generateRunnerInfoVolume())
+	virtualMachineInstance.Spec.Volumes = append(virtualMachineInstance.Spec.Volumes,
+		generateRunnerInfoVolume(secret.Name))
+
+	return virtualMachineInstance, dataVolumes, secret, nil
+}
+
+// getTemplate loads the VirtualMachine used as the runner template from its
+// source. Manifests without a namespace are placed in the template
+// namespace, where their instancetype and preference are looked up.
+func (rc *KubevirtRunner) getTemplate(
+	ctx context.Context,
+	source TemplateSourceKind,
+	vmTemplate, vmTemplateNamespace string,
+) (*v1.VirtualMachine, error) {
+	virtualMachine, err := rc.templateSource(source).LoadTemplate(ctx, vmTemplate, vmTemplateNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if virtualMachine.Namespace == "" {
+		virtualMachine.Namespace = vmTemplateNamespace
+	}
+
+	return virtualMachine, nil
+}
+
+// expandTemplate applies the instancetype and preference referenced by the
+// template to its VMI spec, as the VirtualMachine controller would, since a
+// bare VMI doesn't support them.
+func (rc *KubevirtRunner) expandTemplate(virtualMachine *v1.VirtualMachine) (*v1.VirtualMachine, error) {
+	if virtualMachine.Spec.Instancetype == nil && virtualMachine.Spec.Preference == nil {
+		return virtualMachine, nil
+	}
+
+	utils.GetLogger().Printf("Expanding instancetype and preference of %s template\n", virtualMachine.Name)
+
+	expanded, err := rc.virtClient.ExpandSpec(virtualMachine.Namespace).ForVirtualMachine(virtualMachine)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand instancetype and preference of template %q: %w",
+			virtualMachine.Name, err)
+	}
+
+	// KubeVirt rejects a VirtualMachine that sets both an instancetype and
+	// the CPU or memory it already provides.
+	expanded.Spec.Instancetype = nil
+	expanded.Spec.Preference = nil
 
-	return virtualMachineInstance, dataVolume, nil
+	return expanded, nil
 }
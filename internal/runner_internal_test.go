@@ -25,12 +25,17 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	"go.uber.org/mock/gomock"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"     //nolint:depguard // required by fake reactor signature
+	k8stesting "k8s.io/client-go/testing" //nolint:depguard // required by fake reactor signature
 	v1 "kubevirt.io/api/core/v1"
 	"kubevirt.io/client-go/kubecli"
+	kubevirtfake "kubevirt.io/client-go/kubevirt/fake"
 )
 
 var (
@@ -40,7 +45,7 @@ var (
 )
 
 // TestGetResourcesMarshalJSONError exercises the previously-uncovered error
-// path in getResources where encoding the runner-info annotation payload
+// path in getResources where encoding the runner-info secret payload
 // fails. It swaps the marshalJSON seam to force the failure deterministically.
 func TestGetResourcesMarshalJSONError(t *testing.T) {
 	t.Parallel()
@@ -75,9 +80,10 @@ func TestGetResourcesMarshalJSONError(t *testing.T) {
 		return nil, errSimulatedMarshalFailure
 	}
 
-	vmi, dataVolume, err := runner.getResources(context.Background(), vmTemplate, namespace, runnerName, jitConfig)
+	vmi, dataVolumes, secret, err := runner.getResources(context.Background(), vmTemplate, namespace, runnerName,
+		jitConfig, TemplateOptions{}, ResourceOverrides{}, managementMetadata{})
 	if err == nil {
-		t.Fatal("expected an error when marshalling the runner info annotation payload fails")
+		t.Fatal("expected an error when marshalling the runner info secret payload fails")
 	}
 
 	if !errors.Is(err, errSimulatedMarshalFailure) {
@@ -88,8 +94,12 @@ func TestGetResourcesMarshalJSONError(t *testing.T) {
 		t.Fatalf("expected nil VirtualMachineInstance, got %+v", vmi)
 	}
 
-	if dataVolume != nil {
-		t.Fatalf("expected nil DataVolume, got %+v", dataVolume)
+	if dataVolumes != nil {
+		t.Fatalf("expected nil DataVolumes, got %+v", dataVolumes)
+	}
+
+	if secret != nil {
+		t.Fatalf("expected nil Secret, got %+v", secret)
 	}
 }
 
@@ -150,3 +160,79 @@ func TestCreateExporterStdoutCreationError(t *testing.T) {
 		t.Fatal("expected nil exporter when stdout exporter creation fails")
 	}
 }
+
+// TestDeleteResourcesForcesStalledDeletion exercises the escalation to a zero
+// grace period delete when the graceful deletion of the VMI doesn't complete.
+// It shortens the deletionPollInterval seam to keep the test fast.
+func TestDeleteResourcesForcesStalledDeletion(t *testing.T) {
+	const (
+		namespace  = "default"
+		runnerName = "runner-xyz123"
+	)
+
+	originalInterval := deletionPollInterval
+
+	defer func() { deletionPollInterval = originalInterval }()
+
+	deletionPollInterval = 10 * time.Millisecond
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	virtClientset := kubevirtfake.NewSimpleClientset(&v1.VirtualMachineInstance{
+		ObjectMeta: k8smetav1.ObjectMeta{Name: runnerName, Namespace: namespace},
+	})
+
+	var gracefulDeletes, forcedDeletes int
+
+	virtClientset.PrependReactor("delete", "virtualmachineinstances",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			gracePeriod := action.(k8stesting.DeleteActionImpl).DeleteOptions.GracePeriodSeconds //nolint:forcetypeassert
+			if gracePeriod == nil || *gracePeriod != 0 {
+				gracefulDeletes++
+
+				return true, nil, nil
+			}
+
+			forcedDeletes++
+
+			return false, nil, nil
+		})
+
+	virtClient := kubecli.NewMockKubevirtClient(mockCtrl)
+	virtClient.EXPECT().VirtualMachineInstance(namespace).Return(
+		virtClientset.KubevirtV1().VirtualMachineInstances(namespace)).AnyTimes()
+
+	runner := NewRunner(namespace, virtClient, time.Minute, WithWaitForDeletion(5*time.Second, time.Millisecond))
+
+	NewAppContext(runnerName, nil, "")
+	defer CancelAppContext()
+
+	err := runner.DeleteResources(context.Background())
+	if err != nil {
+		t.Fatalf("expected the forced deletion to remove the VMI, got %v", err)
+	}
+
+	if gracefulDeletes != 1 || forcedDeletes != 1 {
+		t.Fatalf("expected one graceful and one forced delete, got %d and %d", gracefulDeletes, forcedDeletes)
+	}
+}
+
+// TestRetryPolicyBackoff checks that the retry delays grow exponentially,
+// stay within the jitter bounds and never exceed the maximum backoff.
+func TestRetryPolicyBackoff(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	for retry, expected := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 5: 5 * time.Second} {
+		delay := policy.backoff(retry, errSimulatedResourceFailure)
+
+		lower := time.Duration(float64(expected) * (1 - retryJitter))
+		upper := time.Duration(float64(expected) * (1 + retryJitter))
+
+		if delay < lower || delay > upper {
+			t.Fatalf("expected retry %d to wait between %v and %v, got %v", retry, lower, upper, delay)
+		}
+	}
+}
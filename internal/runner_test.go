@@ -20,7 +20,15 @@ package runner_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
@@ -29,22 +37,29 @@ import (
 	"go.uber.org/mock/gomock"
 	k8sv1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime" //nolint:depguard // required by fake reactor signature
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
 	k8stesting "k8s.io/client-go/testing" //nolint:depguard // required by fake reactor signature
 	v1 "kubevirt.io/api/core/v1"
 	cdifake "kubevirt.io/client-go/containerizeddataimporter/fake"
 	"kubevirt.io/client-go/kubecli"
 	kubevirtfake "kubevirt.io/client-go/kubevirt/fake"
+	kvcorev1 "kubevirt.io/client-go/kubevirt/typed/core/v1"
 	"kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 )
 
 var (
 	errSimulatedDataVolumeCreateFailure = errors.New("simulated data volume create failure")
+	errSimulatedSecretCreateFailure     = errors.New("simulated secret create failure")
 	errSimulatedWatchFailure            = errors.New("simulated watch failure")
 	errSimulatedTransientGetFailure     = errors.New("simulated transient get failure")
+	errSimulatedConsoleFailure          = errors.New("simulated serial console failure")
+	errUnscopedEventList                = errors.New("events listed without a field selector")
 )
 
 var _ = Describe("Runner", func() {
@@ -52,6 +67,8 @@ var _ = Describe("Runner", func() {
 
 	var virtClientset *kubevirtfake.Clientset
 
+	var k8sClientset *k8sfake.Clientset
+
 	var karRunner runner.Runner
 
 	var mockCtrl *gomock.Controller
@@ -63,6 +80,7 @@ var _ = Describe("Runner", func() {
 		vmTemplate         = "vm-template"
 		vmInstance         = "runner-xyz123"
 		dataVolume         = "dv-xyz123"
+		secret             = "runner-info-xyz123"
 		kubevirtGroup      = "kubevirt.io"
 		vmiResource        = "virtualmachineinstances"
 	)
@@ -73,7 +91,10 @@ var _ = Describe("Runner", func() {
 		virtClientset = kubevirtfake.NewSimpleClientset(NewVirtualMachineInstance(vmInstance), NewVirtualMachine(vmTemplate))
 		cdiClientset := cdifake.NewSimpleClientset(NewDataVolume(dataVolume))
 
+		k8sClientset = k8sfake.NewSimpleClientset(NewSecret(secret))
+
 		virtClient.EXPECT().CdiClient().Return(cdiClientset).AnyTimes()
+		virtClient.EXPECT().CoreV1().Return(k8sClientset.CoreV1()).AnyTimes()
 
 		karRunner = runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout)
 	})
@@ -99,7 +120,7 @@ var _ = Describe("Runner", func() {
 		}
 
 		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(vmiInterface).AnyTimes()
-		runner.NewAppContext(vmInstance, "")
+		runner.NewAppContext(vmInstance, nil, "")
 
 		errChan := make(chan error, 1)
 
@@ -166,7 +187,8 @@ var _ = Describe("Runner", func() {
 			expectVirtualMachineAndInstance()
 		}
 
-		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerName, jitConfig)
+		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerName, jitConfig,
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
 
 		if shouldSucceed {
 			Expect(err).NotTo(HaveOccurred())
@@ -196,22 +218,191 @@ var _ = Describe("Runner", func() {
 		Entry("when empty jit config is provided", false, vmTemplate, "runnerName", ""),
 	)
 
-	DescribeTable("delete resources", func(vmInstance, dataVolume string) {
+	DescribeTable("delete resources", func(vmInstance string, dataVolumes []string, secret string) {
 		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
 			virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault),
 		)
-		runner.NewAppContext(vmInstance, dataVolume)
+		runner.NewAppContext(vmInstance, dataVolumes, secret)
 
 		err := karRunner.DeleteResources(context.TODO())
 
 		Expect(err).NotTo(HaveOccurred())
 	},
-		Entry("when the runner has a data volume", vmInstance, dataVolume),
-		Entry("when the runner doesn't have data volumes", vmInstance, ""),
-		Entry("when the runner doesn't exist", "runner-abc098", ""),
-		Entry("when the data volume doesn't exist", vmInstance, "dv-abc098"),
+		Entry("when the runner has a data volume", vmInstance, []string{dataVolume}, ""),
+		Entry("when the runner has multiple data volumes", vmInstance, []string{dataVolume, "dv-abc098"}, ""),
+		Entry("when the runner doesn't have data volumes", vmInstance, nil, ""),
+		Entry("when the runner doesn't exist", "runner-abc098", nil, ""),
+		Entry("when the data volume doesn't exist", vmInstance, []string{"dv-abc098"}, ""),
+		Entry("when the runner has a secret", vmInstance, nil, secret),
+		Entry("when the secret doesn't exist", vmInstance, nil, "runner-info-abc098"),
 	)
 
+	It("removes the runner info secret when deleting resources", func() {
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault),
+		)
+		runner.NewAppContext(vmInstance, nil, secret)
+
+		err := karRunner.DeleteResources(context.TODO())
+
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = k8sClientset.CoreV1().Secrets(k8sv1.NamespaceDefault).Get(context.TODO(), secret, metav1.GetOptions{})
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("waits for the runner resources to be gone when deleting resources", func() {
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault),
+		).Times(2)
+		runner.NewAppContext(vmInstance, []string{dataVolume}, secret)
+
+		waitingRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+			runner.WithWaitForDeletion(time.Minute, 0))
+
+		Expect(waitingRunner.DeleteResources(context.TODO())).To(Succeed())
+	})
+
+	It("reports the runner resources left when the cleanup times out", func() {
+		virtClientset.PrependReactor("delete", vmiResource, func(_ k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, nil
+		})
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault),
+		).AnyTimes()
+		runner.NewAppContext(vmInstance, []string{dataVolume}, secret)
+
+		waitingRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+			runner.WithWaitForDeletion(consistencyTimeout, time.Hour))
+
+		err := waitingRunner.DeleteResources(context.TODO())
+
+		Expect(err).To(MatchError(runner.ErrResourcesRemaining))
+		Expect(err).To(MatchError(ContainSubstring(vmInstance)))
+		Expect(err).NotTo(MatchError(ContainSubstring(dataVolume)))
+	})
+
+	Context("when shutting down the guest", func() {
+		const gracePeriod = 30 * time.Second
+
+		It("sets the termination grace period of the created VMI", func() {
+			expectVirtualMachineAndInstance()
+
+			shutdownRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+				runner.WithShutdownGracePeriod(1500*time.Millisecond))
+
+			err := shutdownRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault,
+				"runner-graceful", "jitConfig", runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+			Expect(err).NotTo(HaveOccurred())
+
+			vmi, err := virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).Get(
+				context.TODO(), "runner-graceful", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vmi.Spec.TerminationGracePeriodSeconds).To(HaveValue(BeEquivalentTo(2)))
+		})
+
+		It("deletes the VMI so KubeVirt shuts the guest down", func() {
+			deleted := false
+
+			virtClientset.PrependReactor("delete", vmiResource,
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					deleteAction := action.(k8stesting.DeleteActionImpl) //nolint:forcetypeassert
+					deleted = deleteAction.DeleteOptions.GracePeriodSeconds == nil
+
+					return false, nil, nil
+				})
+			virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+				virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault)).Times(2)
+			runner.NewAppContext(vmInstance, nil, secret)
+
+			shutdownRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout)
+
+			err := shutdownRunner.ShutdownGuest(context.TODO(), gracePeriod)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(BeTrue())
+		})
+
+		It("stops the virtual machine in virtual machine mode", func() {
+			vmInterface := kubecli.NewMockVirtualMachineInterface(mockCtrl)
+			vmInterface.EXPECT().Stop(gomock.Any(), vmInstance, &v1.StopOptions{GracePeriod: new(int64(30))})
+			virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(vmInterface)
+
+			succeededVMI := NewVirtualMachineInstance(vmInstance)
+			succeededVMI.Status.Phase = v1.Succeeded
+			vmiClientset := kubevirtfake.NewSimpleClientset(succeededVMI)
+			virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+				vmiClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault))
+			runner.NewAppContext(vmInstance, nil, secret)
+
+			vmRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+				runner.WithVirtualMachine())
+
+			Expect(vmRunner.ShutdownGuest(context.TODO(), gracePeriod)).To(Succeed())
+		})
+
+		It("rounds a sub-second grace period up to a whole second", func() {
+			vmInterface := kubecli.NewMockVirtualMachineInterface(mockCtrl)
+			vmInterface.EXPECT().Stop(gomock.Any(), vmInstance, &v1.StopOptions{GracePeriod: new(int64(1))})
+			virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(vmInterface)
+			virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+				kubevirtfake.NewSimpleClientset().KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault))
+			runner.NewAppContext(vmInstance, nil, secret)
+
+			vmRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+				runner.WithVirtualMachine())
+
+			Expect(vmRunner.ShutdownGuest(context.TODO(), 300*time.Millisecond)).To(Succeed())
+		})
+
+		It("times out when the guest doesn't power off", func() {
+			virtClientset.PrependReactor("delete", vmiResource,
+				func(_ k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, nil
+				})
+			virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+				virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault)).AnyTimes()
+			runner.NewAppContext(vmInstance, nil, secret)
+
+			shutdownRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout)
+
+			err := shutdownRunner.ShutdownGuest(context.TODO(), consistencyTimeout)
+
+			Expect(err).To(MatchError(runner.ErrShutdownTimeout))
+		})
+	})
+
+	It("preserves the runner resources for debugging", func() {
+		ownedVMI := NewVirtualMachineInstance("runner-preserved")
+		ownedVMI.OwnerReferences = []metav1.OwnerReference{{Kind: "Pod", Name: "runner-pod", UID: "pod-uid"}}
+		Expect(virtClientset.Tracker().Add(ownedVMI)).To(Succeed())
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault))
+		runner.NewAppContext(ownedVMI.Name, []string{dataVolume}, secret)
+
+		preservingRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout)
+
+		Expect(preservingRunner.PreserveResources(context.TODO(), time.Hour)).To(Succeed())
+		Expect(runner.HasAppContext()).To(BeFalse())
+
+		vmi, err := virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).Get(
+			context.TODO(), ownedVMI.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vmi.OwnerReferences).To(BeEmpty())
+		Expect(vmi.Annotations).To(HaveKey(runner.PreserveUntilAnnotation))
+
+		expiry, err := time.Parse(time.RFC3339, vmi.Annotations[runner.PreserveUntilAnnotation])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expiry).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+
+		dv, err := virtClient.CdiClient().CdiV1beta1().DataVolumes(k8sv1.NamespaceDefault).Get(
+			context.TODO(), dataVolume, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dv.Annotations).To(HaveKey(runner.PreserveUntilAnnotation))
+
+		Expect(preservingRunner.DeleteResources(context.TODO())).To(Succeed())
+	})
+
 	It("delete resources does nothing when AppContext is not initialized", func() {
 		// Ensure AppContext is not initialized (AfterEach calls CancelAppContext,
 		// but be explicit here for clarity).
@@ -239,7 +430,7 @@ var _ = Describe("Runner", func() {
 		if shouldSucceed {
 			Eventually(errChan, timeout).Should(Receive(BeNil()))
 		} else {
-			Eventually(errChan, timeout).Should(Receive(Equal(runner.ErrRunnerFailed)))
+			Eventually(errChan, timeout).Should(Receive(MatchError(runner.ErrRunnerFailed)))
 		}
 	},
 		Entry("when the runner completes successfully", true, v1.Succeeded),
@@ -281,7 +472,88 @@ var _ = Describe("Runner", func() {
 		vmi.Status.Phase = v1.Running
 		fakeWatcher.Add(vmi)
 
-		Eventually(errChan, timeout).Should(Receive(MatchError("timeout while waiting for the virtual machine instance")))
+		Eventually(errChan, timeout).Should(Receive(MatchError(runner.ErrWaitTimeout)))
+	})
+
+	DescribeTable("phase timeouts", func(timeouts runner.PhaseTimeouts, phase v1.VirtualMachineInstancePhase,
+		expected error,
+	) {
+		const timeout = eventuallyTimeout
+
+		phaseRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, 300*time.Millisecond,
+			runner.WithPhaseTimeouts(timeouts))
+		fakeWatcher, errChan := startVMIWatcher(phaseRunner)
+
+		vmi := NewVirtualMachineInstance(vmInstance)
+		vmi.Status.Phase = phase
+		fakeWatcher.Add(vmi)
+
+		Eventually(errChan, timeout).Should(Receive(MatchError(expected)))
+	},
+		Entry("when the vmi is stuck scheduling",
+			runner.PhaseTimeouts{Scheduled: 50 * time.Millisecond}, v1.Scheduling, runner.ErrScheduleTimeout),
+		Entry("when the vmi never boots",
+			runner.PhaseTimeouts{Running: 50 * time.Millisecond}, v1.Scheduled, runner.ErrBootTimeout),
+		Entry("when the vmi is running but never ready",
+			runner.PhaseTimeouts{Ready: 50 * time.Millisecond}, v1.Running, runner.ErrReadyTimeout),
+		Entry("when the scheduled milestone is reached in time",
+			runner.PhaseTimeouts{Scheduled: 50 * time.Millisecond}, v1.Scheduled, runner.ErrWaitTimeout),
+		Entry("when the running milestone is reached in time",
+			runner.PhaseTimeouts{Scheduled: 50 * time.Millisecond, Running: 50 * time.Millisecond},
+			v1.Running, runner.ErrWaitTimeout),
+	)
+
+	It("disarms the ready deadline once the VMI is Running and Ready", func() {
+		const timeout = eventuallyTimeout
+
+		phaseRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, 300*time.Millisecond,
+			runner.WithPhaseTimeouts(runner.PhaseTimeouts{Ready: 50 * time.Millisecond}))
+		fakeWatcher, errChan := startVMIWatcher(phaseRunner)
+
+		fakeWatcher.Modify(NewVirtualMachineInstanceReady(vmInstance))
+
+		Eventually(errChan, timeout).Should(Receive(MatchError(runner.ErrWaitTimeout)))
+	})
+
+	Context("when the VMI is unschedulable", func() {
+		newUnschedulableVMI := func() *v1.VirtualMachineInstance {
+			vmi := NewVirtualMachineInstance(vmInstance)
+			vmi.Status.Phase = v1.Scheduling
+			vmi.Status.Conditions = []v1.VirtualMachineInstanceCondition{{
+				Type:    v1.VirtualMachineInstanceConditionType(k8sv1.PodScheduled),
+				Status:  k8sv1.ConditionFalse,
+				Reason:  k8sv1.PodReasonUnschedulable,
+				Message: "0/3 nodes are available: 3 Insufficient memory.",
+			}}
+
+			return vmi
+		}
+
+		It("fails once the grace period is over", func() {
+			phaseRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, 300*time.Millisecond,
+				runner.WithPhaseTimeouts(runner.PhaseTimeouts{Unschedulable: 50 * time.Millisecond}))
+			fakeWatcher, errChan := startVMIWatcher(phaseRunner)
+
+			fakeWatcher.Add(newUnschedulableVMI())
+
+			Eventually(errChan, eventuallyTimeout).Should(Receive(MatchError(runner.ErrUnschedulable)))
+		})
+
+		It("keeps waiting once the VMI gets scheduled", func() {
+			phaseRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, 300*time.Millisecond,
+				runner.WithPhaseTimeouts(runner.PhaseTimeouts{Unschedulable: 100 * time.Millisecond}))
+			fakeWatcher, errChan := startVMIWatcher(phaseRunner)
+
+			vmi := newUnschedulableVMI()
+			fakeWatcher.Add(vmi)
+
+			vmi = vmi.DeepCopy()
+			vmi.Status.Phase = v1.Scheduled
+			vmi.Status.Conditions = nil
+			fakeWatcher.Modify(vmi)
+
+			Eventually(errChan, eventuallyTimeout).Should(Receive(MatchError(runner.ErrWaitTimeout)))
+		})
 	})
 
 	It("re-establishes the VMI watch when the watch stream closes", func() {
@@ -342,7 +614,7 @@ var _ = Describe("Runner", func() {
 			}).AnyTimes()
 
 		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(vmiInterface).AnyTimes()
-		runner.NewAppContext(vmInstance, "")
+		runner.NewAppContext(vmInstance, nil, "")
 
 		errChan := make(chan error, 1)
 		go func() {
@@ -351,13 +623,13 @@ var _ = Describe("Runner", func() {
 			close(errChan)
 		}()
 
-		Eventually(errChan, timeout).Should(Receive(MatchError("timeout while waiting for the virtual machine instance")))
+		Eventually(errChan, timeout).Should(Receive(MatchError(runner.ErrWaitTimeout)))
 	})
 
 	It("exits immediately when the context is already cancelled on entry", func() {
 		vmiInterface := kubecli.NewMockVirtualMachineInstanceInterface(mockCtrl)
 		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(vmiInterface)
-		runner.NewAppContext(vmInstance, "")
+		runner.NewAppContext(vmInstance, nil, "")
 
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
@@ -430,25 +702,233 @@ var _ = Describe("Runner", func() {
 		Eventually(errChan, timeout).Should(Receive(BeNil()))
 	})
 
+	startSerialConsoleWatcher := func(
+		serialConsole func() (kvcorev1.StreamInterface, error),
+	) (*watch.FakeWatcher, chan error) {
+		consoleRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+			runner.WithSerialConsole())
+		fakeWatcher := watch.NewFake()
+
+		vmiInterface := kubecli.NewMockVirtualMachineInstanceInterface(mockCtrl)
+		vmiInterface.EXPECT().Get(gomock.Any(), vmInstance, gomock.Any()).Return(
+			NewVirtualMachineInstance(vmInstance), nil).AnyTimes()
+		vmiInterface.EXPECT().Watch(gomock.Any(), gomock.Any()).Return(fakeWatcher, nil)
+		vmiInterface.EXPECT().SerialConsole(vmInstance, gomock.Any()).DoAndReturn(
+			func(_ string, _ *kvcorev1.SerialConsoleOptions) (kvcorev1.StreamInterface, error) {
+				return serialConsole()
+			}).AnyTimes()
+
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(vmiInterface).AnyTimes()
+		runner.NewAppContext(vmInstance, nil, "")
+
+		errChan := make(chan error, 1)
+
+		go func() {
+			errChan <- consoleRunner.WaitForVirtualMachineInstance(context.TODO())
+
+			close(errChan)
+		}()
+
+		return fakeWatcher, errChan
+	}
+
+	It("reconnects to the serial console when the stream drops", func() {
+		var connections atomic.Int32
+
+		fakeWatcher, errChan := startSerialConsoleWatcher(func() (kvcorev1.StreamInterface, error) {
+			connections.Add(1)
+
+			return &fakeConsoleStream{output: "boot line 1\r\nboot line 2\n"}, nil
+		})
+
+		Eventually(connections.Load, 3*time.Second).Should(BeNumerically(">=", 2))
+
+		vmi := NewVirtualMachineInstance(vmInstance)
+		vmi.Status.Phase = v1.Succeeded
+		fakeWatcher.Modify(vmi)
+
+		Eventually(errChan, eventuallyTimeout).Should(Receive(BeNil()))
+	})
+
+	It("keeps waiting for the VMI when the serial console cannot be reached", func() {
+		var connections atomic.Int32
+
+		fakeWatcher, errChan := startSerialConsoleWatcher(func() (kvcorev1.StreamInterface, error) {
+			connections.Add(1)
+
+			return nil, errSimulatedConsoleFailure
+		})
+
+		Eventually(connections.Load, 3*time.Second).Should(BeNumerically(">=", 2))
+		Consistently(errChan, consistencyTimeout).ShouldNot(Receive())
+
+		vmi := NewVirtualMachineInstance(vmInstance)
+		vmi.Status.Phase = v1.Failed
+		fakeWatcher.Modify(vmi)
+
+		Eventually(errChan, eventuallyTimeout).Should(Receive(MatchError(runner.ErrRunnerFailed)))
+	})
+
+	It("writes a diagnostics bundle when the VMI fails", func() {
+		failedVMI := NewVirtualMachineInstance(vmInstance)
+		failedVMI.UID = "vmi-uid"
+		failedVMI.Status.Phase = v1.Failed
+		failedVMI.Status.Conditions = []v1.VirtualMachineInstanceCondition{{
+			Type:    v1.VirtualMachineInstanceReady,
+			Status:  k8sv1.ConditionFalse,
+			Message: "guest crashed",
+		}}
+		vmiClientset := kubevirtfake.NewSimpleClientset(failedVMI)
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			vmiClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault)).AnyTimes()
+
+		launcherPod := &k8sv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "virt-launcher-" + vmInstance,
+				Namespace: k8sv1.NamespaceDefault,
+				Labels:    map[string]string{v1.CreatedByLabel: "vmi-uid"},
+			},
+			Status: k8sv1.PodStatus{ContainerStatuses: []k8sv1.ContainerStatus{{
+				Name: "compute",
+				State: k8sv1.ContainerState{
+					Terminated: &k8sv1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+				},
+			}}},
+		}
+		_, err := k8sClientset.CoreV1().Pods(k8sv1.NamespaceDefault).Create(
+			context.TODO(), launcherPod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = k8sClientset.CoreV1().Events(k8sv1.NamespaceDefault).Create(context.TODO(), &k8sv1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "vmi-event", Namespace: k8sv1.NamespaceDefault},
+			InvolvedObject: k8sv1.ObjectReference{Name: vmInstance},
+			Type:           k8sv1.EventTypeWarning,
+			Reason:         "SyncFailed",
+			Message:        "server error",
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = k8sClientset.CoreV1().Events(k8sv1.NamespaceDefault).Create(context.TODO(), &k8sv1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "other-runner-event", Namespace: k8sv1.NamespaceDefault},
+			InvolvedObject: k8sv1.ObjectReference{Name: "runner-other"},
+			Type:           k8sv1.EventTypeWarning,
+			Reason:         "SyncFailed",
+			Message:        "other team",
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		// The fake clientset ignores field selectors, so apply them like the API server.
+		k8sClientset.PrependReactor("list", "events",
+			func(action k8stesting.Action) (bool, runtime.Object, error) {
+				selector := action.(k8stesting.ListAction).GetListRestrictions().Fields
+				if selector.Empty() {
+					return true, nil, errUnscopedEventList
+				}
+
+				obj, err := k8sClientset.Tracker().List(
+					k8sv1.SchemeGroupVersion.WithResource("events"),
+					k8sv1.SchemeGroupVersion.WithKind("Event"), k8sv1.NamespaceDefault)
+				if err != nil {
+					return true, nil, err
+				}
+
+				events := obj.(*k8sv1.EventList)
+				events.Items = slices.DeleteFunc(events.Items, func(event k8sv1.Event) bool {
+					return !selector.Matches(fields.Set{"involvedObject.name": event.InvolvedObject.Name})
+				})
+
+				return true, events, nil
+			})
+
+		runner.NewAppContext(vmInstance, []string{dataVolume}, secret)
+
+		diagnosticsDir := GinkgoT().TempDir()
+		diagnosticsRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+			runner.WithDiagnosticsDir(diagnosticsDir))
+
+		err = diagnosticsRunner.WaitForVirtualMachineInstance(context.TODO())
+		Expect(err).To(MatchError(runner.ErrRunnerFailed))
+
+		data, err := os.ReadFile(filepath.Join(diagnosticsDir, vmInstance+"-diagnostics.json"))
+		Expect(err).NotTo(HaveOccurred())
+
+		var bundle runner.DiagnosticsBundle
+		Expect(json.Unmarshal(data, &bundle)).To(Succeed())
+		Expect(bundle.VirtualMachineInstance).NotTo(BeNil())
+		Expect(bundle.LauncherPods).To(HaveLen(1))
+		Expect(bundle.DataVolumes).To(HaveLen(1))
+		Expect(bundle.Events).To(HaveExactElements(HaveField("InvolvedObject.Name", vmInstance)))
+		Expect(bundle.CollectionErrors).NotTo(ContainElement(ContainSubstring("list events")))
+		Expect(bundle.Summary).To(ContainSubstring("guest crashed"))
+		Expect(bundle.Summary).To(ContainSubstring("OOMKilled"))
+		Expect(bundle.Summary).To(ContainSubstring("last warning: runner-xyz123 SyncFailed: server error"))
+	})
+
+	It("explains why the VMI failed", func() {
+		failedVMI := NewVirtualMachineInstance(vmInstance)
+		failedVMI.Status.Phase = v1.Failed
+		failedVMI.Status.Conditions = []v1.VirtualMachineInstanceCondition{{
+			Type:    v1.VirtualMachineInstanceConditionType(k8sv1.PodScheduled),
+			Status:  k8sv1.ConditionFalse,
+			Reason:  k8sv1.PodReasonUnschedulable,
+			Message: "0/3 nodes are available: 3 Insufficient devices.kubevirt.io/kvm.",
+		}}
+		vmiClientset := kubevirtfake.NewSimpleClientset(failedVMI)
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			vmiClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault)).AnyTimes()
+
+		failedDataVolume := NewDataVolume("dv-failed")
+		failedDataVolume.Status.Conditions = []v1beta1.DataVolumeCondition{{
+			Type:    v1beta1.DataVolumeRunning,
+			Status:  k8sv1.ConditionFalse,
+			Reason:  "Error",
+			Message: "Unable to connect to http data source",
+		}}
+		failingCDIVirtClient := kubecli.NewMockKubevirtClient(mockCtrl)
+		failingCDIVirtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			vmiClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault)).AnyTimes()
+		failingCDIVirtClient.EXPECT().CdiClient().Return(cdifake.NewSimpleClientset(failedDataVolume)).AnyTimes()
+		failingCDIVirtClient.EXPECT().CoreV1().Return(k8sClientset.CoreV1()).AnyTimes()
+		runner.NewAppContext(vmInstance, []string{"dv-failed"}, secret)
+
+		failureRunner := runner.NewRunner(k8sv1.NamespaceDefault, failingCDIVirtClient, defaultWaitTimeout)
+
+		err := failureRunner.WaitForVirtualMachineInstance(context.TODO())
+		Expect(err).To(MatchError(runner.ErrRunnerFailed))
+
+		var failure *runner.VMIFailureError
+		Expect(errors.As(err, &failure)).To(BeTrue())
+		Expect(failure.VMIName).To(Equal(vmInstance))
+		Expect(failure.Phase).To(Equal(v1.Failed))
+		Expect(failure.Unschedulable).To(ConsistOf(
+			"unschedulable: 0/3 nodes are available: 3 Insufficient devices.kubevirt.io/kvm."))
+		Expect(failure.DataVolumeErrors).To(ConsistOf(
+			"data volume dv-failed: Unable to connect to http data source"))
+		Expect(err.Error()).To(HavePrefix("runner has failed: phase Failed; unschedulable: "))
+	})
+
 	It("returns an error when the referenced virtual machine template does not exist", func() {
 		virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
 			virtClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault))
 
 		err := karRunner.CreateResources(
-			context.TODO(), "nonexistent-template", k8sv1.NamespaceDefault, "runnerName", "jitConfig")
+			context.TODO(), "nonexistent-template", k8sv1.NamespaceDefault, "runnerName", "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
 
 		Expect(err).To(HaveOccurred())
 		Expect(err).To(MatchError(ContainSubstring("failed to get KubeVirt virtual machine template")))
+		Expect(err).To(MatchError(runner.ErrTemplateNotFound))
 	})
 
 	It("returns an error when VMI creation fails", func() {
 		mockVMIInterface := kubecli.NewMockVirtualMachineInstanceInterface(mockCtrl)
 		mockVMIInterface.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(
-			nil, k8serrors.NewServiceUnavailable("simulated create failure"))
+			nil, k8serrors.NewForbidden(schema.GroupResource{Group: kubevirtGroup, Resource: vmiResource}, "runner-new", nil))
 
 		expectVirtualMachineWithVMIInterface(mockVMIInterface)
 
-		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, "runner-new", "jitConfig")
+		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, "runner-new", "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
 
 		Expect(err).To(HaveOccurred())
 		Expect(err).To(MatchError(ContainSubstring("failed to create runner instance")))
@@ -457,7 +937,8 @@ var _ = Describe("Runner", func() {
 	It("defaults the vm template namespace when it is empty", func() {
 		expectVirtualMachineAndInstance()
 
-		err := karRunner.CreateResources(context.TODO(), vmTemplate, "", "runner-default-ns", "jitConfig")
+		err := karRunner.CreateResources(context.TODO(), vmTemplate, "", "runner-default-ns", "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
 
 		Expect(err).NotTo(HaveOccurred())
 
@@ -470,10 +951,13 @@ var _ = Describe("Runner", func() {
 		mockVMIInterface.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			nil, k8serrors.NewAlreadyExists(
 				schema.GroupResource{Group: kubevirtGroup, Resource: vmiResource}, "runner-existing"))
+		mockVMIInterface.EXPECT().Get(gomock.Any(), "runner-existing", gomock.Any()).Return(
+			NewVirtualMachineInstance("runner-existing"), nil)
 
 		expectVirtualMachineWithVMIInterface(mockVMIInterface)
 
-		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, "runner-existing", "jitConfig")
+		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, "runner-existing", "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
 
 		Expect(err).NotTo(HaveOccurred())
 
@@ -486,7 +970,7 @@ var _ = Describe("Runner", func() {
 
 		const runnerWithDV = "runner-with-dv-failure"
 
-		dvVM := NewVirtualMachineWithDataVolume(vmTemplate, dvTemplateName)
+		dvVM := NewVirtualMachineWithDataVolumes(vmTemplate, dvTemplateName)
 		dvClientset := kubevirtfake.NewSimpleClientset(dvVM)
 		failingCdiClientset := cdifake.NewSimpleClientset()
 		failingCdiClientset.PrependReactor("create", "datavolumes", func(_ k8stesting.Action) (bool, runtime.Object, error) {
@@ -495,6 +979,7 @@ var _ = Describe("Runner", func() {
 
 		failingVirtClient := kubecli.NewMockKubevirtClient(mockCtrl)
 		failingVirtClient.EXPECT().CdiClient().Return(failingCdiClientset).AnyTimes()
+		failingVirtClient.EXPECT().CoreV1().Return(k8sClientset.CoreV1()).AnyTimes()
 		failingVirtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
 			dvClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault))
 		failingVirtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
@@ -502,18 +987,76 @@ var _ = Describe("Runner", func() {
 
 		failingRunner := runner.NewRunner(k8sv1.NamespaceDefault, failingVirtClient, defaultWaitTimeout)
 
-		err := failingRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerWithDV, "jitConfig")
+		err := failingRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerWithDV, "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
 
 		Expect(err).To(HaveOccurred())
 		Expect(err).To(MatchError(ContainSubstring("cannot create data volume")))
 	})
 
+	DescribeTable("resolves a data volume creation stored before its retry", func(foreign bool) {
+		const runnerWithDV = "runner-with-dv-retry"
+
+		dvClientset := kubevirtfake.NewSimpleClientset(NewVirtualMachineWithDataVolumes(vmTemplate, "boot-disk"))
+		retryCdiClientset := cdifake.NewSimpleClientset()
+		attempts := 0
+
+		if foreign {
+			existing := NewDataVolume("boot-disk-" + runnerWithDV)
+			existing.OwnerReferences = []metav1.OwnerReference{{Kind: "VirtualMachineInstance", UID: "another-uid"}}
+			Expect(retryCdiClientset.Tracker().Add(existing)).To(Succeed())
+		}
+
+		retryCdiClientset.PrependReactor("create", "datavolumes",
+			func(action k8stesting.Action) (bool, runtime.Object, error) {
+				attempts++
+				if attempts > 1 {
+					return false, nil, nil
+				}
+
+				if !foreign {
+					createAction := action.(k8stesting.CreateAction) //nolint:forcetypeassert
+					Expect(retryCdiClientset.Tracker().Add(createAction.GetObject())).To(Succeed())
+				}
+
+				return true, nil, k8serrors.NewServerTimeout(schema.GroupResource{Resource: "datavolumes"}, "create", 1)
+			})
+
+		retryVirtClient := kubecli.NewMockKubevirtClient(mockCtrl)
+		retryVirtClient.EXPECT().CdiClient().Return(retryCdiClientset).AnyTimes()
+		retryVirtClient.EXPECT().CoreV1().Return(k8sClientset.CoreV1()).AnyTimes()
+		retryVirtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			dvClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault))
+		retryVirtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			dvClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault))
+
+		retryingRunner := runner.NewRunner(k8sv1.NamespaceDefault, retryVirtClient, defaultWaitTimeout,
+			runner.WithRetryPolicy(runner.RetryPolicy{
+				MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond,
+			}))
+
+		err := retryingRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerWithDV,
+			"jitConfig", runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+
+		Expect(attempts).To(Equal(2))
+
+		if foreign {
+			Expect(err).To(MatchError(runner.ErrResourceConflict))
+		} else {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(runner.GetAppContext().GetDataVolumeNames()).To(ConsistOf("boot-disk-" + runnerWithDV))
+		}
+	},
+		Entry("when the stored data volume belongs to the runner", false),
+		Entry("when the stored data volume belongs to another runner", true),
+	)
+
 	It("creates resources that include a data volume template", func() {
 		const dvTemplateName = "boot-disk"
 
 		const runnerWithDV = "runner-with-dv"
 
-		dvVM := NewVirtualMachineWithDataVolume(vmTemplate, dvTemplateName)
+		dvVM := NewVirtualMachineWithDataVolumes(vmTemplate, dvTemplateName)
 		dvClientset := kubevirtfake.NewSimpleClientset(dvVM)
 
 		virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
@@ -521,92 +1064,852 @@ var _ = Describe("Runner", func() {
 		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
 			virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault))
 
-		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerWithDV, "jitConfig")
+		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerWithDV, "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
 
 		Expect(err).NotTo(HaveOccurred())
 
 		appCtx := runner.GetAppContext()
-		Expect(appCtx.GetDataVolumeName()).To(ContainSubstring(dvTemplateName))
+		Expect(appCtx.GetDataVolumeNames()).To(ConsistOf(ContainSubstring(dvTemplateName)))
 	})
 
-	It("logs but does not return an error when VMI delete fails with a non-NotFound error", func() {
-		forbiddenErr := k8serrors.NewForbidden(
-			schema.GroupResource{Group: kubevirtGroup, Resource: vmiResource},
-			vmInstance, nil)
+	It("creates one data volume per data volume template", func() {
+		const runnerWithDVs = "runner-with-dvs"
 
-		mockVMIInterface := kubecli.NewMockVirtualMachineInstanceInterface(mockCtrl)
-		mockVMIInterface.EXPECT().Delete(gomock.Any(), vmInstance, gomock.Any()).Return(forbiddenErr)
-		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(mockVMIInterface)
-		runner.NewAppContext(vmInstance, "")
+		dvVM := NewVirtualMachineWithDataVolumes(vmTemplate, "boot-disk", "scratch-disk")
+		dvClientset := kubevirtfake.NewSimpleClientset(dvVM)
+		cdiClientset := cdifake.NewSimpleClientset()
 
-		err := karRunner.DeleteResources(context.TODO())
+		multiDVVirtClient := kubecli.NewMockKubevirtClient(mockCtrl)
+		multiDVVirtClient.EXPECT().CdiClient().Return(cdiClientset).AnyTimes()
+		multiDVVirtClient.EXPECT().CoreV1().Return(k8sClientset.CoreV1()).AnyTimes()
+		multiDVVirtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			dvClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault))
+		multiDVVirtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			dvClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault))
+
+		multiDVRunner := runner.NewRunner(k8sv1.NamespaceDefault, multiDVVirtClient, defaultWaitTimeout)
+
+		err := multiDVRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerWithDVs, "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
 
 		Expect(err).NotTo(HaveOccurred())
-	})
 
-	It("logs but does not return an error when data volume delete fails with a non-NotFound error", func() {
-		forbiddenErr := k8serrors.NewForbidden(
-			schema.GroupResource{Group: "cdi.kubevirt.io", Resource: "datavolumes"}, dataVolume, nil)
+		expectedNames := []string{"boot-disk-" + runnerWithDVs, "scratch-disk-" + runnerWithDVs}
+		Expect(runner.GetAppContext().GetDataVolumeNames()).To(Equal(expectedNames))
 
-		failingCdiClientset := cdifake.NewSimpleClientset(NewDataVolume(dataVolume))
-		failingCdiClientset.PrependReactor("delete", "datavolumes", func(_ k8stesting.Action) (bool, runtime.Object, error) {
-			return true, nil, forbiddenErr
-		})
+		for _, name := range expectedNames {
+			_, err := cdiClientset.CdiV1beta1().DataVolumes(k8sv1.NamespaceDefault).Get(
+				context.TODO(), name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		}
 
-		mockVMIInterface := kubecli.NewMockVirtualMachineInstanceInterface(mockCtrl)
-		mockVMIInterface.EXPECT().Delete(gomock.Any(), vmInstance, gomock.Any()).Return(nil)
+		vmi, err := dvClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).Get(
+			context.TODO(), runnerWithDVs, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
 
-		failingVirtClient := kubecli.NewMockKubevirtClient(mockCtrl)
-		failingVirtClient.EXPECT().CdiClient().Return(failingCdiClientset).AnyTimes()
-		failingVirtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(mockVMIInterface)
+		var volumeDataVolumes []string
 
-		failingRunner := runner.NewRunner(k8sv1.NamespaceDefault, failingVirtClient, defaultWaitTimeout)
+		for _, volume := range vmi.Spec.Volumes {
+			if volume.DataVolume != nil {
+				volumeDataVolumes = append(volumeDataVolumes, volume.DataVolume.Name)
+			}
+		}
+
+		Expect(volumeDataVolumes).To(Equal(expectedNames))
+	})
 
-		runner.NewAppContext(vmInstance, dataVolume)
+	It("delivers the jit config through a secret owned by the VMI", func() {
+		const runnerWithSecret = "runner-with-secret"
 
-		err := failingRunner.DeleteResources(context.TODO())
+		expectVirtualMachineAndInstance()
+
+		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerWithSecret, "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+
+		Expect(err).NotTo(HaveOccurred())
+
+		appCtx := runner.GetAppContext()
+		Expect(appCtx.GetSecretName()).To(Equal("runner-info-" + runnerWithSecret))
+
+		createdSecret, err := k8sClientset.CoreV1().Secrets(k8sv1.NamespaceDefault).Get(
+			context.TODO(), appCtx.GetSecretName(), metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(createdSecret.Data).To(HaveKeyWithValue("runner-info.json", []byte(`{"jitconfig":"jitConfig"}`)))
+		Expect(createdSecret.OwnerReferences).To(HaveLen(1))
+		Expect(createdSecret.OwnerReferences[0].Kind).To(Equal("VirtualMachineInstance"))
+		Expect(createdSecret.OwnerReferences[0].Name).To(Equal(runnerWithSecret))
 
+		vmi, err := virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).Get(
+			context.TODO(), runnerWithSecret, metav1.GetOptions{})
 		Expect(err).NotTo(HaveOccurred())
+		Expect(vmi.Annotations).To(BeEmpty())
+		Expect(vmi.Spec.Volumes).To(ContainElement(v1.Volume{
+			Name: "runner-info",
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{SecretName: appCtx.GetSecretName()},
+			},
+		}))
 	})
 
-	It("returns an error when watching the virtual machine instance fails", func() {
-		vmiInterface := kubecli.NewMockVirtualMachineInstanceInterface(mockCtrl)
-		vmiInterface.EXPECT().Get(gomock.Any(), vmInstance, gomock.Any()).Return(
-			NewVirtualMachineInstance(vmInstance), nil).AnyTimes()
-		vmiInterface.EXPECT().Watch(gomock.Any(), gomock.Any()).Return(nil, errSimulatedWatchFailure)
+	It("returns an error when the runner info secret creation fails", func() {
+		k8sClientset.PrependReactor("create", "secrets", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errSimulatedSecretCreateFailure
+		})
 
-		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(vmiInterface).AnyTimes()
-		runner.NewAppContext(vmInstance, "")
+		expectVirtualMachineAndInstance()
 
-		err := karRunner.WaitForVirtualMachineInstance(context.TODO())
+		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, "runner-new", "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
 
-		Expect(err).To(MatchError(ContainSubstring("failed to watch the virtual machine instance")))
+		Expect(err).To(MatchError(ContainSubstring("cannot create runner info secret")))
 	})
 
-	It("treats a context cancellation during the VMI Get call as a timeout", func() {
-		ctx, cancel := context.WithCancel(context.Background())
-
-		vmiInterface := kubecli.NewMockVirtualMachineInstanceInterface(mockCtrl)
-		vmiInterface.EXPECT().Get(gomock.Any(), vmInstance, gomock.Any()).DoAndReturn(
-			func(_ context.Context, _ string, _ metav1.GetOptions) (*v1.VirtualMachineInstance, error) {
-				// Simulate the context being cancelled concurrently with the API
-				// call failing, exercising the race between ctx.Done() and the
-				// Get error path.
-				cancel()
+	It("replaces the runner info secret when it already exists", func() {
+		stale := NewSecret(secret)
+		stale.Data = map[string][]byte{"runner-info.json": []byte(`{"jitconfig":"staleConfig"}`)}
+		stale.OwnerReferences = []metav1.OwnerReference{{Kind: "VirtualMachineInstance", Name: "previous-runner"}}
+		_, err := k8sClientset.CoreV1().Secrets(k8sv1.NamespaceDefault).Update(context.TODO(), stale, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred())
 
-				return nil, errSimulatedTransientGetFailure
-			}).AnyTimes()
+		expectVirtualMachineAndInstance()
 
-		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(vmiInterface).AnyTimes()
-		runner.NewAppContext(vmInstance, "")
+		err = karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, "xyz123", "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
 
-		err := karRunner.WaitForVirtualMachineInstance(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runner.GetAppContext().GetSecretName()).To(Equal(secret))
 
-		Expect(err).To(MatchError("timeout while waiting for the virtual machine instance"))
+		replaced, err := k8sClientset.CoreV1().Secrets(k8sv1.NamespaceDefault).Get(
+			context.TODO(), secret, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replaced.Data).To(HaveKeyWithValue("runner-info.json", []byte(`{"jitconfig":"jitConfig"}`)))
+		Expect(replaced.OwnerReferences).To(HaveLen(1))
+		Expect(replaced.OwnerReferences[0].Name).To(Equal("xyz123"))
 	})
 
-	It("fails when the VMI disappears before a watch can be re-established", func() {
-		firstWatcher := watch.NewFake()
+	It("returns an error when the existing runner info secret cannot be replaced", func() {
+		k8sClientset.PrependReactor("update", "secrets", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errSimulatedSecretCreateFailure
+		})
+
+		expectVirtualMachineAndInstance()
+
+		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, "xyz123", "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+
+		Expect(err).To(MatchError(errSimulatedSecretCreateFailure))
+	})
+
+	It("retries the runner info secret creation after a transient error", func() {
+		attempts := 0
+
+		k8sClientset.PrependReactor("create", "secrets", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+			attempts++
+			if attempts == 1 {
+				return true, nil, k8serrors.NewServiceUnavailable(errSimulatedSecretCreateFailure.Error())
+			}
+
+			return false, nil, nil
+		})
+		expectVirtualMachineAndInstance()
+
+		retryingRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+			runner.WithRetryPolicy(runner.RetryPolicy{
+				MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond,
+			}))
+
+		err := retryingRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, "runner-retry",
+			"jitConfig", runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attempts).To(Equal(2))
+
+		_, err = k8sClientset.CoreV1().Secrets(k8sv1.NamespaceDefault).Get(
+			context.TODO(), "runner-info-runner-retry", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("retries the VMI creation after transient errors", func(failures int, shouldSucceed bool) {
+		attempts := 0
+
+		virtClientset.PrependReactor("create", vmiResource, func(_ k8stesting.Action) (bool, runtime.Object, error) {
+			attempts++
+			if attempts <= failures {
+				return true, nil, k8serrors.NewInternalError(errSimulatedTransientGetFailure)
+			}
+
+			return false, nil, nil
+		})
+		expectVirtualMachineAndInstance()
+
+		retryingRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+			runner.WithRetryPolicy(runner.RetryPolicy{
+				MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond,
+			}))
+
+		err := retryingRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, "runner-retry",
+			"jitConfig", runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+
+		if shouldSucceed {
+			Expect(err).NotTo(HaveOccurred())
+		} else {
+			Expect(k8serrors.IsInternalError(err)).To(BeTrue())
+		}
+
+		Expect(attempts).To(Equal(min(failures+1, 3)))
+	},
+		Entry("when a retry succeeds", 2, true),
+		Entry("when the attempts are exhausted", 5, false),
+	)
+
+	It("returns an error when the existing VMI cannot be retrieved", func() {
+		mockVMIInterface := kubecli.NewMockVirtualMachineInstanceInterface(mockCtrl)
+		mockVMIInterface.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+			nil, k8serrors.NewAlreadyExists(
+				schema.GroupResource{Group: kubevirtGroup, Resource: vmiResource}, "runner-existing"))
+		mockVMIInterface.EXPECT().Get(gomock.Any(), "runner-existing", gomock.Any()).Return(
+			nil, k8serrors.NewServiceUnavailable("simulated get failure"))
+
+		expectVirtualMachineWithVMIInterface(mockVMIInterface)
+
+		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, "runner-existing", "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+
+		Expect(err).To(MatchError(ContainSubstring("failed to get existing runner instance")))
+	})
+
+	It("expands the instancetype and preference of the template into the VMI spec", func() {
+		const runnerWithInstancetype = "runner-with-instancetype"
+
+		template := NewVirtualMachine(vmTemplate)
+		template.Spec.Instancetype = &v1.InstancetypeMatcher{Name: "u1.medium"}
+		template.Spec.Preference = &v1.PreferenceMatcher{Name: "fedora", Kind: "VirtualMachinePreference"}
+		templateClientset := kubevirtfake.NewSimpleClientset(template)
+
+		expanded := template.DeepCopy()
+		expanded.Spec.Template.Spec.Domain.CPU = &v1.CPU{Sockets: 1, Cores: 2}
+
+		expandSpec := kubecli.NewMockExpandSpecInterface(mockCtrl)
+		expandSpec.EXPECT().ForVirtualMachine(gomock.Any()).DoAndReturn(
+			func(vm *v1.VirtualMachine) (*v1.VirtualMachine, error) {
+				Expect(vm.Spec.Instancetype.Name).To(Equal("u1.medium"))
+
+				return expanded, nil
+			})
+
+		virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			templateClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault))
+		virtClient.EXPECT().ExpandSpec(k8sv1.NamespaceDefault).Return(expandSpec)
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			templateClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault))
+
+		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault,
+			runnerWithInstancetype, "jitConfig", runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+
+		Expect(err).NotTo(HaveOccurred())
+
+		vmi, err := templateClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).Get(
+			context.TODO(), runnerWithInstancetype, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vmi.Spec.Domain.CPU).To(Equal(&v1.CPU{Sockets: 1, Cores: 2}))
+	})
+
+	It("returns an error when the instancetype of the template cannot be expanded", func() {
+		template := NewVirtualMachine(vmTemplate)
+		template.Spec.Instancetype = &v1.InstancetypeMatcher{Name: "missing"}
+		templateClientset := kubevirtfake.NewSimpleClientset(template)
+
+		expandSpec := kubecli.NewMockExpandSpecInterface(mockCtrl)
+		expandSpec.EXPECT().ForVirtualMachine(gomock.Any()).Return(
+			nil, k8serrors.NewBadRequest("instancetype not found"))
+
+		virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			templateClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault))
+		virtClient.EXPECT().ExpandSpec(k8sv1.NamespaceDefault).Return(expandSpec)
+
+		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, "runner-new", "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+
+		Expect(err).To(MatchError(ContainSubstring("failed to expand instancetype and preference")))
+	})
+
+	It("applies the resource overrides on top of the template", func() {
+		const runnerWithOverrides = "runner-with-overrides"
+
+		template := NewVirtualMachineWithDataVolumes(vmTemplate, "boot-disk", "scratch-disk")
+		template.Spec.Template.Spec.Domain.CPU = &v1.CPU{Sockets: 2, Cores: 1, Threads: 2}
+		template.Spec.Template.Spec.Domain.Resources.Requests = k8sv1.ResourceList{
+			k8sv1.ResourceMemory: resource.MustParse("2Gi"),
+		}
+		templateClientset := kubevirtfake.NewSimpleClientset(template)
+		cdiClientset := cdifake.NewSimpleClientset()
+
+		overridesVirtClient := kubecli.NewMockKubevirtClient(mockCtrl)
+		overridesVirtClient.EXPECT().CdiClient().Return(cdiClientset).AnyTimes()
+		overridesVirtClient.EXPECT().CoreV1().Return(k8sClientset.CoreV1()).AnyTimes()
+		overridesVirtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			templateClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault))
+		overridesVirtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			templateClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault))
+
+		overrides, err := runner.NewResourceOverrides(4, "8Gi", "50Gi")
+		Expect(err).NotTo(HaveOccurred())
+
+		overridesRunner := runner.NewRunner(k8sv1.NamespaceDefault, overridesVirtClient, defaultWaitTimeout)
+
+		err = overridesRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault,
+			runnerWithOverrides, "jitConfig", runner.TemplateOptions{}, overrides, runner.ResourceMetadata{})
+
+		Expect(err).NotTo(HaveOccurred())
+
+		vmi, err := templateClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).Get(
+			context.TODO(), runnerWithOverrides, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vmi.Spec.Domain.CPU).To(Equal(&v1.CPU{Sockets: 1, Cores: 4, Threads: 1}))
+		Expect(vmi.Spec.Domain.Memory.Guest.String()).To(Equal("8Gi"))
+		Expect(vmi.Spec.Domain.Resources.Requests.Memory().String()).To(Equal("8Gi"))
+
+		bootDisk, err := cdiClientset.CdiV1beta1().DataVolumes(k8sv1.NamespaceDefault).Get(
+			context.TODO(), "boot-disk-"+runnerWithOverrides, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bootDisk.Spec.Storage.Resources.Requests.Storage().String()).To(Equal("50Gi"))
+
+		scratchDisk, err := cdiClientset.CdiV1beta1().DataVolumes(k8sv1.NamespaceDefault).Get(
+			context.TODO(), "scratch-disk-"+runnerWithOverrides, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scratchDisk.Spec.Storage).To(BeNil())
+	})
+
+	DescribeTable("validates the resource overrides against the limits", func(cpuCores uint32,
+		memory, diskSize string,
+	) {
+		limitedRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+			runner.WithResourceLimits(runner.ResourceLimits{
+				MinCPUCores: 2,
+				MaxCPUCores: 8,
+				MinMemory:   new(resource.MustParse("2Gi")),
+				MaxMemory:   new(resource.MustParse("16Gi")),
+				MaxDiskSize: new(resource.MustParse("100Gi")),
+			}))
+
+		overrides, err := runner.NewResourceOverrides(cpuCores, memory, diskSize)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = limitedRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault,
+			"runner-limited", "jitConfig", runner.TemplateOptions{}, overrides, runner.ResourceMetadata{})
+
+		Expect(err).To(MatchError(runner.ErrInvalidResourceOverride))
+	},
+		Entry("when the cpu cores are below the minimum", uint32(1), "", ""),
+		Entry("when the cpu cores are above the maximum", uint32(16), "", ""),
+		Entry("when the memory is below the minimum", uint32(0), "1Gi", ""),
+		Entry("when the memory is above the maximum", uint32(4), "32Gi", ""),
+		Entry("when the disk size is above the maximum", uint32(0), "", "1Ti"),
+	)
+
+	DescribeTable("rejects malformed resource overrides", func(memory, diskSize string) {
+		_, err := runner.NewResourceOverrides(0, memory, diskSize)
+
+		Expect(err).To(MatchError(runner.ErrInvalidResourceOverride))
+	},
+		Entry("when the memory is malformed", "lots", ""),
+		Entry("when the disk size is malformed", "", "big"),
+	)
+
+	It("applies the resource overrides to the runner virtual machine", func() {
+		const runnerWithVM = "runner-vm-overrides"
+
+		template := NewVirtualMachineWithDataVolumes(vmTemplate, "boot-disk")
+		template.Spec.DataVolumeTemplates[0].Spec.PVC = &k8sv1.PersistentVolumeClaimSpec{}
+		templateClientset := kubevirtfake.NewSimpleClientset(template)
+
+		virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			templateClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault)).Times(2)
+
+		overrides, err := runner.NewResourceOverrides(2, "4Gi", "20Gi")
+		Expect(err).NotTo(HaveOccurred())
+
+		vmRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+			runner.WithVirtualMachine())
+
+		err = vmRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerWithVM,
+			"jitConfig", runner.TemplateOptions{}, overrides, runner.ResourceMetadata{})
+
+		Expect(err).NotTo(HaveOccurred())
+
+		vm, err := templateClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault).Get(
+			context.TODO(), runnerWithVM, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vm.Spec.Template.Spec.Domain.CPU.Cores).To(Equal(uint32(2)))
+		Expect(vm.Spec.Template.Spec.Domain.Memory.Guest.String()).To(Equal("4Gi"))
+		Expect(vm.Spec.DataVolumeTemplates[0].Spec.PVC.Resources.Requests.Storage().String()).To(Equal("20Gi"))
+	})
+
+	It("labels the runner resources", func() {
+		const runnerWithLabels = "runner-with-labels"
+
+		dvVM := NewVirtualMachineWithDataVolumes(vmTemplate, "boot-disk")
+		dvClientset := kubevirtfake.NewSimpleClientset(dvVM)
+		cdiClientset := cdifake.NewSimpleClientset()
+
+		labelsVirtClient := kubecli.NewMockKubevirtClient(mockCtrl)
+		labelsVirtClient.EXPECT().CdiClient().Return(cdiClientset).AnyTimes()
+		labelsVirtClient.EXPECT().CoreV1().Return(k8sClientset.CoreV1()).AnyTimes()
+		labelsVirtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			dvClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault))
+		labelsVirtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+			dvClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault))
+
+		labelsRunner := runner.NewRunner(k8sv1.NamespaceDefault, labelsVirtClient, defaultWaitTimeout)
+
+		err := labelsRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerWithLabels,
+			"jitConfig", runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{
+				GitHubRepository: "octo-org/octo-repo",
+				GitHubWorkflow:   "Build and test",
+				GitHubRunID:      "1234",
+				Labels:           map[string]string{"team": "infra", runner.ManagedByLabel: "someone-else"},
+				Annotations:      map[string]string{"example.com/owner": "infra"},
+			})
+
+		Expect(err).NotTo(HaveOccurred())
+
+		expectedLabels := map[string]string{
+			runner.ManagedByLabel:         runner.ManagedByValue,
+			runner.RunnerNameLabel:        runnerWithLabels,
+			runner.TemplateLabel:          vmTemplate,
+			runner.TemplateNamespaceLabel: k8sv1.NamespaceDefault,
+			runner.GitHubRepositoryLabel:  "octo-org_octo-repo",
+			runner.GitHubWorkflowLabel:    "Build_and_test",
+			runner.GitHubRunIDLabel:       "1234",
+			"team":                        "infra",
+		}
+
+		vmi, err := dvClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).Get(
+			context.TODO(), runnerWithLabels, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vmi.Labels).To(Equal(expectedLabels))
+		Expect(vmi.Annotations).To(HaveKeyWithValue("example.com/owner", "infra"))
+
+		dataVolume, err := cdiClientset.CdiV1beta1().DataVolumes(k8sv1.NamespaceDefault).Get(
+			context.TODO(), "boot-disk-"+runnerWithLabels, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dataVolume.Labels).To(Equal(expectedLabels))
+	})
+
+	It("fails without creating resources when an extra label is invalid", func() {
+		err := karRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, vmInstance, "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{},
+			runner.ResourceMetadata{Labels: map[string]string{"team": "infra/ci"}})
+
+		Expect(err).To(MatchError(runner.ErrInvalidResourceMetadata))
+		Expect(runner.HasAppContext()).To(BeFalse())
+	})
+
+	It("expands the instancetype of the template before sizing the virtual machine", func() {
+		const runnerWithVM = "runner-with-vm-instancetype"
+
+		template := NewVirtualMachine(vmTemplate)
+		template.Spec.Instancetype = &v1.InstancetypeMatcher{Name: "u1.medium"}
+		vmClientset := kubevirtfake.NewSimpleClientset(template)
+
+		expanded := template.DeepCopy()
+		expanded.Spec.Template.Spec.Domain.CPU = &v1.CPU{Sockets: 1, Cores: 2}
+
+		expandSpec := kubecli.NewMockExpandSpecInterface(mockCtrl)
+		expandSpec.EXPECT().ForVirtualMachine(gomock.Any()).Return(expanded, nil)
+
+		vmVirtClient := kubecli.NewMockKubevirtClient(mockCtrl)
+		vmVirtClient.EXPECT().CoreV1().Return(k8sClientset.CoreV1()).AnyTimes()
+		vmVirtClient.EXPECT().ExpandSpec(k8sv1.NamespaceDefault).Return(expandSpec)
+		vmVirtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			vmClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault)).Times(2)
+
+		vmRunner := runner.NewRunner(k8sv1.NamespaceDefault, vmVirtClient, defaultWaitTimeout,
+			runner.WithVirtualMachine())
+
+		err := vmRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerWithVM, "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{CPUCores: 4}, runner.ResourceMetadata{})
+
+		Expect(err).NotTo(HaveOccurred())
+
+		vm, err := vmClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault).Get(
+			context.TODO(), runnerWithVM, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vm.Spec.Instancetype).To(BeNil())
+		Expect(vm.Spec.Template.Spec.Domain.CPU).To(Equal(&v1.CPU{Sockets: 1, Cores: 4, Threads: 1}))
+	})
+
+	It("creates a virtual machine with the Once run strategy in virtual machine mode", func() {
+		const runnerWithVM = "runner-with-vm"
+
+		dvVM := NewVirtualMachineWithDataVolumes(vmTemplate, "boot-disk")
+		vmClientset := kubevirtfake.NewSimpleClientset(dvVM)
+		cdiClientset := cdifake.NewSimpleClientset()
+
+		vmVirtClient := kubecli.NewMockKubevirtClient(mockCtrl)
+		vmVirtClient.EXPECT().CdiClient().Return(cdiClientset).AnyTimes()
+		vmVirtClient.EXPECT().CoreV1().Return(k8sClientset.CoreV1()).AnyTimes()
+		vmVirtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			vmClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault)).Times(2)
+
+		vmRunner := runner.NewRunner(k8sv1.NamespaceDefault, vmVirtClient, defaultWaitTimeout,
+			runner.WithVirtualMachine())
+
+		err := vmRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerWithVM, "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+
+		Expect(err).NotTo(HaveOccurred())
+
+		appCtx := runner.GetAppContext()
+		Expect(appCtx.GetVMIName()).To(Equal(runnerWithVM))
+		Expect(appCtx.GetDataVolumeNames()).To(ConsistOf("boot-disk-" + runnerWithVM))
+
+		vm, err := vmClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault).Get(
+			context.TODO(), runnerWithVM, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vm.Spec.RunStrategy).To(HaveValue(Equal(v1.RunStrategyOnce)))
+		Expect(vm.Spec.DataVolumeTemplates).To(HaveLen(1))
+		Expect(vm.Spec.DataVolumeTemplates[0].Name).To(Equal("boot-disk-" + runnerWithVM))
+		Expect(vm.Spec.Template.Spec.Volumes).To(ContainElements(
+			v1.Volume{
+				Name: "disk0",
+				VolumeSource: v1.VolumeSource{
+					DataVolume: &v1.DataVolumeSource{Name: "boot-disk-" + runnerWithVM},
+				},
+			},
+			v1.Volume{
+				Name: "runner-info",
+				VolumeSource: v1.VolumeSource{
+					Secret: &v1.SecretVolumeSource{SecretName: appCtx.GetSecretName()},
+				},
+			},
+		))
+
+		template, err := vmClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault).Get(
+			context.TODO(), vmTemplate, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(template.Spec.DataVolumeTemplates[0].Name).To(Equal("boot-disk"))
+
+		dataVolumes, err := cdiClientset.CdiV1beta1().DataVolumes(k8sv1.NamespaceDefault).List(
+			context.TODO(), metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dataVolumes.Items).To(BeEmpty())
+
+		createdSecret, err := k8sClientset.CoreV1().Secrets(k8sv1.NamespaceDefault).Get(
+			context.TODO(), appCtx.GetSecretName(), metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(createdSecret.OwnerReferences).To(HaveLen(1))
+		Expect(createdSecret.OwnerReferences[0].Kind).To(Equal("VirtualMachine"))
+		Expect(createdSecret.OwnerReferences[0].Name).To(Equal(runnerWithVM))
+	})
+
+	It("returns an error when the runner virtual machine creation fails", func() {
+		virtClientset.PrependReactor("create", "virtualmachines", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, k8serrors.NewForbidden(
+				schema.GroupResource{Group: kubevirtGroup, Resource: "virtualmachines"}, "runner-new", nil)
+		})
+		virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault)).Times(2)
+
+		vmRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+			runner.WithVirtualMachine())
+
+		err := vmRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, "runner-new", "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+
+		Expect(err).To(MatchError(ContainSubstring("failed to create runner virtual machine")))
+	})
+
+	It("reuses the runner virtual machine when it already exists", func() {
+		existing := NewVirtualMachine(vmInstance)
+		existing.UID = "existing-vm-uid"
+
+		Expect(virtClientset.Tracker().Add(existing)).To(Succeed())
+		virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault)).Times(2)
+
+		vmRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+			runner.WithVirtualMachine())
+
+		err := vmRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, vmInstance, "jitConfig",
+			runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+
+		Expect(err).NotTo(HaveOccurred())
+
+		createdSecret, err := k8sClientset.CoreV1().Secrets(k8sv1.NamespaceDefault).Get(
+			context.TODO(), "runner-info-"+vmInstance, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(createdSecret.OwnerReferences[0].UID).To(BeEquivalentTo("existing-vm-uid"))
+	})
+
+	It("deletes the runner virtual machine in virtual machine mode", func() {
+		Expect(virtClientset.Tracker().Add(NewVirtualMachine(vmInstance))).To(Succeed())
+		virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+			virtClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault))
+		runner.NewAppContext(vmInstance, nil, secret)
+
+		vmRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+			runner.WithVirtualMachine())
+
+		err := vmRunner.DeleteResources(context.TODO())
+
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = virtClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault).Get(
+			context.TODO(), vmInstance, metav1.GetOptions{})
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+
+		_, err = k8sClientset.CoreV1().Secrets(k8sv1.NamespaceDefault).Get(context.TODO(), secret, metav1.GetOptions{})
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("waits for the VMI to be created by the virtual machine", func() {
+		const timeout = eventuallyTimeout
+
+		fakeWatcher := watch.NewFake()
+		vmRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+			runner.WithVirtualMachine())
+		errChan := startVMIWatcherWithGet(vmRunner, func() (*v1.VirtualMachineInstance, error) {
+			return nil, k8serrors.NewNotFound(
+				schema.GroupResource{Group: kubevirtGroup, Resource: vmiResource}, vmInstance)
+		}, fakeWatcher)
+
+		Consistently(errChan, consistencyTimeout).ShouldNot(Receive())
+
+		vmi := NewVirtualMachineInstance(vmInstance)
+		vmi.Status.Phase = v1.Succeeded
+		fakeWatcher.Add(vmi)
+
+		Eventually(errChan, timeout).Should(Receive(BeNil()))
+	})
+
+	DescribeTable("makes the runner pod the owner of the runner", func(podNamespace string, vmMode bool,
+		expectedOwners int,
+	) {
+		const runnerOwned = "runner-owned"
+
+		pod := &k8sv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "runner-pod", Namespace: podNamespace, UID: "pod-uid"}}
+		opts := []runner.Option{runner.WithRunnerPod(pod), runner.WithPodOwner()}
+
+		var getOwners func() []metav1.OwnerReference
+
+		if vmMode {
+			opts = append(opts, runner.WithVirtualMachine())
+
+			virtClient.EXPECT().VirtualMachine(k8sv1.NamespaceDefault).Return(
+				virtClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault)).Times(2)
+
+			getOwners = func() []metav1.OwnerReference {
+				vm, err := virtClientset.KubevirtV1().VirtualMachines(k8sv1.NamespaceDefault).Get(
+					context.TODO(), runnerOwned, metav1.GetOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				return vm.OwnerReferences
+			}
+		} else {
+			expectVirtualMachineAndInstance()
+
+			getOwners = func() []metav1.OwnerReference {
+				vmi, err := virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault).Get(
+					context.TODO(), runnerOwned, metav1.GetOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				return vmi.OwnerReferences
+			}
+		}
+
+		ownedRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout, opts...)
+
+		err := ownedRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault, runnerOwned,
+			"jitConfig", runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+		Expect(err).NotTo(HaveOccurred())
+
+		owners := getOwners()
+		Expect(owners).To(HaveLen(expectedOwners))
+
+		if expectedOwners > 0 {
+			Expect(owners[0]).To(Equal(metav1.OwnerReference{
+				APIVersion: "v1",
+				Kind:       "Pod",
+				Name:       "runner-pod",
+				UID:        "pod-uid",
+			}))
+		}
+	},
+		Entry("when the runner is a VMI", k8sv1.NamespaceDefault, false, 1),
+		Entry("when the runner is a virtual machine", k8sv1.NamespaceDefault, true, 1),
+		Entry("when the pod is in another namespace", "runners", false, 0),
+	)
+
+	Context("with pod events", func() {
+		const runnerPod = "runner-pod"
+
+		var events *runner.PodEventRecorder
+
+		eventReasons := func() []string {
+			list, err := k8sClientset.CoreV1().Events(k8sv1.NamespaceDefault).List(
+				context.TODO(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			reasons := make([]string, 0, len(list.Items))
+			for _, event := range list.Items {
+				Expect(event.InvolvedObject.Name).To(Equal(runnerPod))
+
+				reasons = append(reasons, event.Reason)
+			}
+
+			return reasons
+		}
+
+		BeforeEach(func() {
+			pod := &k8sv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: runnerPod, Namespace: k8sv1.NamespaceDefault}}
+
+			events = runner.NewPodEventRecorder(k8sClientset.CoreV1(), pod)
+			DeferCleanup(events.Shutdown)
+		})
+
+		It("records the creation and deletion of the runner resources", func() {
+			eventsRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+				runner.WithEventRecorder(events))
+
+			expectVirtualMachineAndInstance()
+
+			err := eventsRunner.CreateResources(context.TODO(), vmTemplate, k8sv1.NamespaceDefault,
+				"runner-events", "jitConfig", runner.TemplateOptions{}, runner.ResourceOverrides{}, runner.ResourceMetadata{})
+			Expect(err).NotTo(HaveOccurred())
+
+			virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(
+				virtClientset.KubevirtV1().VirtualMachineInstances(k8sv1.NamespaceDefault))
+
+			Expect(eventsRunner.DeleteResources(context.TODO())).To(Succeed())
+
+			Eventually(eventReasons, eventuallyTimeout).Should(ConsistOf(
+				runner.EventReasonCreated, runner.EventReasonCreated,
+				runner.EventReasonDeleted, runner.EventReasonDeleted,
+			))
+		})
+
+		It("writes the recorded events before shutting down", func() {
+			pod := &k8sv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: runnerPod, Namespace: k8sv1.NamespaceDefault}}
+			recorder := runner.NewPodEventRecorder(k8sClientset.CoreV1(), pod)
+
+			recorder.Normalf(runner.EventReasonCreated, "created %s", "runner-flush")
+			recorder.Warningf(runner.EventReasonTimeout, "timed out")
+			recorder.Shutdown()
+
+			Expect(eventReasons()).To(ConsistOf(runner.EventReasonCreated, runner.EventReasonTimeout))
+		})
+
+		It("records the phase transitions and the Ready milestone of the VMI", func() {
+			eventsRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, defaultWaitTimeout,
+				runner.WithEventRecorder(events))
+			fakeWatcher, errChan := startVMIWatcher(eventsRunner)
+
+			vmi := NewVirtualMachineInstance(vmInstance)
+			vmi.Status.Phase = v1.Scheduling
+			fakeWatcher.Add(vmi.DeepCopy())
+
+			readyVMI := NewVirtualMachineInstanceReady(vmInstance)
+			fakeWatcher.Modify(readyVMI.DeepCopy())
+
+			waitForWatchCompletion(errChan, eventuallyTimeout, readyVMI, func(vmi *v1.VirtualMachineInstance) {
+				fakeWatcher.Modify(vmi.DeepCopy())
+			})
+
+			Eventually(eventReasons, eventuallyTimeout).Should(ContainElements(
+				runner.EventReasonPhaseTransition, runner.EventReasonReady, runner.EventReasonSucceeded,
+			))
+		})
+
+		It("records a warning when the VMI times out", func() {
+			eventsRunner := runner.NewRunner(k8sv1.NamespaceDefault, virtClient, 100*time.Millisecond,
+				runner.WithEventRecorder(events))
+			_, errChan := startVMIWatcher(eventsRunner)
+
+			Eventually(errChan, eventuallyTimeout).Should(Receive(MatchError(runner.ErrWaitTimeout)))
+			Eventually(eventReasons, eventuallyTimeout).Should(ContainElement(runner.EventReasonTimeout))
+		})
+	})
+
+	It("logs but does not return an error when VMI delete fails with a non-NotFound error", func() {
+		forbiddenErr := k8serrors.NewForbidden(
+			schema.GroupResource{Group: kubevirtGroup, Resource: vmiResource},
+			vmInstance, nil)
+
+		mockVMIInterface := kubecli.NewMockVirtualMachineInstanceInterface(mockCtrl)
+		mockVMIInterface.EXPECT().Delete(gomock.Any(), vmInstance, gomock.Any()).Return(forbiddenErr)
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(mockVMIInterface)
+		runner.NewAppContext(vmInstance, nil, "")
+
+		err := karRunner.DeleteResources(context.TODO())
+
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("logs but does not return an error when data volume delete fails with a non-NotFound error", func() {
+		forbiddenErr := k8serrors.NewForbidden(
+			schema.GroupResource{Group: "cdi.kubevirt.io", Resource: "datavolumes"}, dataVolume, nil)
+
+		failingCdiClientset := cdifake.NewSimpleClientset(NewDataVolume(dataVolume))
+		failingCdiClientset.PrependReactor("delete", "datavolumes", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, forbiddenErr
+		})
+
+		mockVMIInterface := kubecli.NewMockVirtualMachineInstanceInterface(mockCtrl)
+		mockVMIInterface.EXPECT().Delete(gomock.Any(), vmInstance, gomock.Any()).Return(nil)
+
+		failingVirtClient := kubecli.NewMockKubevirtClient(mockCtrl)
+		failingVirtClient.EXPECT().CdiClient().Return(failingCdiClientset).AnyTimes()
+		failingVirtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(mockVMIInterface)
+
+		failingRunner := runner.NewRunner(k8sv1.NamespaceDefault, failingVirtClient, defaultWaitTimeout)
+
+		runner.NewAppContext(vmInstance, []string{dataVolume}, "")
+
+		err := failingRunner.DeleteResources(context.TODO())
+
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("returns an error when watching the virtual machine instance fails", func() {
+		vmiInterface := kubecli.NewMockVirtualMachineInstanceInterface(mockCtrl)
+		vmiInterface.EXPECT().Get(gomock.Any(), vmInstance, gomock.Any()).Return(
+			NewVirtualMachineInstance(vmInstance), nil).AnyTimes()
+		vmiInterface.EXPECT().Watch(gomock.Any(), gomock.Any()).Return(nil, errSimulatedWatchFailure)
+
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(vmiInterface).AnyTimes()
+		runner.NewAppContext(vmInstance, nil, "")
+
+		err := karRunner.WaitForVirtualMachineInstance(context.TODO())
+
+		Expect(err).To(MatchError(ContainSubstring("failed to watch the virtual machine instance")))
+	})
+
+	It("treats a context cancellation during the VMI Get call as a timeout", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		vmiInterface := kubecli.NewMockVirtualMachineInstanceInterface(mockCtrl)
+		vmiInterface.EXPECT().Get(gomock.Any(), vmInstance, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, _ metav1.GetOptions) (*v1.VirtualMachineInstance, error) {
+				// Simulate the context being cancelled concurrently with the API
+				// call failing, exercising the race between ctx.Done() and the
+				// Get error path.
+				cancel()
+
+				return nil, errSimulatedTransientGetFailure
+			}).AnyTimes()
+
+		virtClient.EXPECT().VirtualMachineInstance(k8sv1.NamespaceDefault).Return(vmiInterface).AnyTimes()
+		runner.NewAppContext(vmInstance, nil, "")
+
+		err := karRunner.WaitForVirtualMachineInstance(ctx)
+
+		Expect(err).To(MatchError("timeout while waiting for the virtual machine instance"))
+	})
+
+	It("fails when the VMI disappears before a watch can be re-established", func() {
+		firstWatcher := watch.NewFake()
 		getCalls := 0
 		errChan := startVMIWatcherWithGet(karRunner, func() (*v1.VirtualMachineInstance, error) {
 			getCalls++
@@ -627,6 +1930,22 @@ var _ = Describe("Runner", func() {
 	})
 })
 
+// fakeConsoleStream emulates a serial console connection that emits its
+// output and then drops.
+type fakeConsoleStream struct {
+	output string
+}
+
+func (f *fakeConsoleStream) Stream(options kvcorev1.StreamOptions) error {
+	_, err := io.WriteString(options.Out, f.output)
+
+	return err
+}
+
+func (f *fakeConsoleStream) AsConn() net.Conn {
+	return nil
+}
+
 func NewVirtualMachine(name string) *v1.VirtualMachine {
 	return &v1.VirtualMachine{
 		ObjectMeta: metav1.ObjectMeta{
@@ -668,6 +1987,15 @@ func NewVirtualMachineInstanceReady(name string) *v1.VirtualMachineInstance {
 	}
 }
 
+func NewSecret(name string) *k8sv1.Secret {
+	return &k8sv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: k8sv1.NamespaceDefault,
+		},
+	}
+}
+
 func NewDataVolume(name string) *v1beta1.DataVolume {
 	return &v1beta1.DataVolume{
 		ObjectMeta: metav1.ObjectMeta{
@@ -677,30 +2005,34 @@ func NewDataVolume(name string) *v1beta1.DataVolume {
 	}
 }
 
-func NewVirtualMachineWithDataVolume(name, dvName string) *v1.VirtualMachine {
+func NewVirtualMachineWithDataVolumes(name string, dvNames ...string) *v1.VirtualMachine {
+	dataVolumeTemplates := make([]v1.DataVolumeTemplateSpec, 0, len(dvNames))
+	volumes := make([]v1.Volume, 0, len(dvNames))
+
+	for i, dvName := range dvNames {
+		dataVolumeTemplates = append(dataVolumeTemplates, v1.DataVolumeTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Name: dvName},
+		})
+		volumes = append(volumes, v1.Volume{
+			Name: fmt.Sprintf("disk%d", i),
+			VolumeSource: v1.VolumeSource{
+				DataVolume: &v1.DataVolumeSource{
+					Name: dvName,
+				},
+			},
+		})
+	}
+
 	return &v1.VirtualMachine{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: k8sv1.NamespaceDefault,
 		},
 		Spec: v1.VirtualMachineSpec{
-			DataVolumeTemplates: []v1.DataVolumeTemplateSpec{
-				{
-					ObjectMeta: metav1.ObjectMeta{Name: dvName},
-				},
-			},
+			DataVolumeTemplates: dataVolumeTemplates,
 			Template: &v1.VirtualMachineInstanceTemplateSpec{
 				Spec: v1.VirtualMachineInstanceSpec{
-					Volumes: []v1.Volume{
-						{
-							Name: "disk0",
-							VolumeSource: v1.VolumeSource{
-								DataVolume: &v1.DataVolumeSource{
-									Name: dvName,
-								},
-							},
-						},
-					},
+					Volumes: volumes,
 				},
 			},
 		},
@@ -0,0 +1,145 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "kubevirt.io/api/core/v1"
+)
+
+// GuestShutdowner powers off the runner guest before its resources are
+// deleted, giving the in-guest runner a chance to deregister from GitHub.
+type GuestShutdowner interface {
+	ShutdownGuest(ctx context.Context, gracePeriod time.Duration) error
+}
+
+var _ GuestShutdowner = (*KubevirtRunner)(nil)
+
+// WithShutdownGracePeriod sets the termination grace period of the runner
+// VMI, rounded up to whole seconds. KubeVirt sends the guest an ACPI shutdown
+// when the VMI is deleted and only kills it once this period is over.
+func WithShutdownGracePeriod(gracePeriod time.Duration) Option {
+	return func(rc *KubevirtRunner) {
+		rc.shutdownGracePeriod = gracePeriod
+	}
+}
+
+// gracePeriodSeconds rounds gracePeriod up, so a sub-second grace period
+// doesn't turn into an immediate kill.
+func gracePeriodSeconds(gracePeriod time.Duration) int64 {
+	return int64(math.Ceil(gracePeriod.Seconds()))
+}
+
+// applyShutdownGracePeriod makes the guest's termination grace period the
+// one configured through WithShutdownGracePeriod.
+func (rc *KubevirtRunner) applyShutdownGracePeriod(spec *v1.VirtualMachineInstanceSpec) {
+	if rc.shutdownGracePeriod <= 0 {
+		return
+	}
+
+	spec.TerminationGracePeriodSeconds = new(gracePeriodSeconds(rc.shutdownGracePeriod))
+}
+
+// ShutdownGuest requests an ACPI shutdown of the runner and waits up to
+// gracePeriod, rounded up to whole seconds, for the guest to power off. The
+// VirtualMachine is stopped through its stop subresource in virtual machine
+// mode. KubeVirt has no such subresource for a bare VMI, and ignores the grace
+// period of a delete request, so the VMI is deleted instead and the guest gets
+// the termination grace period set by WithShutdownGracePeriod.
+func (rc *KubevirtRunner) ShutdownGuest(ctx context.Context, gracePeriod time.Duration) error {
+	tracer := otel.Tracer(tracerName)
+
+	ctx, span := tracer.Start(ctx, "ShutdownGuest",
+		trace.WithAttributes(attribute.String("gracePeriod", gracePeriod.String())),
+	)
+	defer span.End()
+
+	if !HasAppContext() {
+		return nil
+	}
+
+	vmiName := GetAppContext().GetVMIName()
+	seconds := gracePeriodSeconds(gracePeriod)
+	gracePeriod = time.Duration(seconds) * time.Second
+
+	utils.GetLogger().Printf("Shutting down %s guest within %v\n", vmiName, gracePeriod)
+	span.SetAttributes(attribute.String("vmiName", vmiName))
+
+	var err error
+	if rc.virtualMachine {
+		err = rc.virtClient.VirtualMachine(rc.namespace).Stop(ctx, vmiName, &v1.StopOptions{GracePeriod: &seconds})
+	} else {
+		err = rc.virtClient.VirtualMachineInstance(rc.namespace).Delete(ctx, vmiName, k8smetav1.DeleteOptions{})
+	}
+
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		span.RecordError(err)
+
+		return fmt.Errorf("cannot request the shutdown of %s: %w", vmiName, err)
+	}
+
+	rc.events.Normalf(EventReasonShuttingDown, "Shutting down virtual machine instance %s", vmiName)
+
+	err = rc.awaitGuestShutdown(ctx, vmiName, gracePeriod)
+	if err != nil {
+		span.RecordError(err)
+
+		return err
+	}
+
+	span.AddEvent("guest_shutdown")
+
+	return nil
+}
+
+// awaitGuestShutdown polls the VMI until it is gone or in a final phase.
+func (rc *KubevirtRunner) awaitGuestShutdown(ctx context.Context, vmiName string, gracePeriod time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, gracePeriod)
+	defer cancel()
+
+	ticker := time.NewTicker(deletionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		vmi, err := rc.virtClient.VirtualMachineInstance(rc.namespace).Get(ctx, vmiName, k8smetav1.GetOptions{})
+		if k8serrors.IsNotFound(err) || (err == nil && vmi.IsFinal()) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %s", ErrShutdownTimeout, vmiName)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,272 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	k8scorev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	v1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// TemplateOptions selects where the template of a runner is loaded from and
+// how its disks are created. Zero values load a VirtualMachine and restore
+// the disks from the snapshots it references.
+type TemplateOptions struct {
+	// Source selects where the template is loaded from; empty selects
+	// TemplateSourceVirtualMachine.
+	Source TemplateSourceKind
+	// DiskRestoreStrategy selects how the disks are created from the snapshots
+	// referenced by the template; empty selects DiskRestoreSnapshot.
+	DiskRestoreStrategy DiskRestoreStrategy
+}
+
+// TemplateSourceKind selects where the runner template is loaded from. The
+// template name is interpreted according to it.
+type TemplateSourceKind string
+
+const (
+	// TemplateSourceVirtualMachine loads the template from a VirtualMachine
+	// named after the template, in the template namespace. It is the default.
+	TemplateSourceVirtualMachine TemplateSourceKind = "virtualmachine"
+	// TemplateSourceFile loads the template from the YAML or JSON
+	// VirtualMachine manifest whose path is the template name.
+	TemplateSourceFile TemplateSourceKind = "file"
+	// TemplateSourceConfigMap loads the template from the VirtualMachine
+	// manifest stored in a ConfigMap of the template namespace, with a
+	// template name of the form <configmap>/<key>.
+	TemplateSourceConfigMap TemplateSourceKind = "configmap"
+	// TemplateSourceClusterTemplate loads the template from the cluster-scoped
+	// VirtualMachineClusterTemplate named after the template.
+	TemplateSourceClusterTemplate TemplateSourceKind = "clustertemplate"
+	// TemplateSourceOCI loads the template from the YAML or JSON
+	// VirtualMachine manifest published as an OCI artifact, with a template
+	// name of the form <registry>/<repository>:<tag> or @<digest>.
+	TemplateSourceOCI TemplateSourceKind = "oci"
+)
+
+// VirtualMachineClusterTemplateResource is the cluster-scoped resource read
+// by TemplateSourceClusterTemplate. Its spec.virtualMachineTemplate holds the
+// metadata and the spec of the VirtualMachine used as template.
+var VirtualMachineClusterTemplateResource = schema.GroupVersionResource{
+	Group:    "kubevirt-actions-runner.electrocucaracha.io",
+	Version:  "v1alpha1",
+	Resource: "virtualmachineclustertemplates",
+}
+
+// ParseTemplateSource validates a template source. An empty value is kept,
+// which selects TemplateSourceVirtualMachine.
+func ParseTemplateSource(value string) (TemplateSourceKind, error) {
+	switch source := TemplateSourceKind(value); source {
+	case "", TemplateSourceVirtualMachine, TemplateSourceFile, TemplateSourceConfigMap,
+		TemplateSourceClusterTemplate, TemplateSourceOCI:
+		return source, nil
+	default:
+		return "", fmt.Errorf("%w: unknown source %q", ErrInvalidTemplateSource, value)
+	}
+}
+
+// TemplateSource loads the VirtualMachine used as the runner template.
+type TemplateSource interface {
+	LoadTemplate(ctx context.Context, name, namespace string) (*v1.VirtualMachine, error)
+}
+
+// templateSource returns the implementation of a template source.
+func (rc *KubevirtRunner) templateSource(kind TemplateSourceKind) TemplateSource {
+	switch kind {
+	case TemplateSourceFile:
+		return fileTemplateSource{}
+	case TemplateSourceConfigMap:
+		return configMapTemplateSource{rc: rc}
+	case TemplateSourceClusterTemplate:
+		return clusterTemplateSource{rc: rc}
+	case TemplateSourceOCI:
+		return ociTemplateSource{rc: rc}
+	default:
+		return virtualMachineTemplateSource{rc: rc}
+	}
+}
+
+type virtualMachineTemplateSource struct {
+	rc *KubevirtRunner
+}
+
+func (s virtualMachineTemplateSource) LoadTemplate(
+	ctx context.Context,
+	name, namespace string,
+) (*v1.VirtualMachine, error) {
+	virtualMachine, err := withRetry(ctx, s.rc.retryPolicy, "get virtual machine template",
+		func() (*v1.VirtualMachine, error) {
+			return s.rc.virtClient.VirtualMachine(namespace).Get(ctx, name, k8smetav1.GetOptions{})
+		})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			err = fmt.Errorf("%w: %w", ErrTemplateNotFound, err)
+		}
+
+		return nil, fmt.Errorf(
+			"failed to get KubeVirt virtual machine template %q in namespace %q: %w",
+			name,
+			namespace,
+			err,
+		)
+	}
+
+	return virtualMachine, nil
+}
+
+type fileTemplateSource struct{}
+
+func (fileTemplateSource) LoadTemplate(_ context.Context, name, _ string) (*v1.VirtualMachine, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = fmt.Errorf("%w: %w", ErrTemplateNotFound, err)
+		}
+
+		return nil, fmt.Errorf("failed to read virtual machine template file %q: %w", name, err)
+	}
+
+	virtualMachine, err := decodeTemplate(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode virtual machine template file %q: %w", name, err)
+	}
+
+	return virtualMachine, nil
+}
+
+type configMapTemplateSource struct {
+	rc *KubevirtRunner
+}
+
+func (s configMapTemplateSource) LoadTemplate(
+	ctx context.Context,
+	name, namespace string,
+) (*v1.VirtualMachine, error) {
+	configMapName, key, found := strings.Cut(name, "/")
+	if !found || configMapName == "" || key == "" {
+		return nil, fmt.Errorf("%w: config map template %q isn't of the form <configmap>/<key>",
+			ErrInvalidTemplateSource, name)
+	}
+
+	configMap, err := withRetry(ctx, s.rc.retryPolicy, "get template config map",
+		func() (*k8scorev1.ConfigMap, error) {
+			return s.rc.virtClient.CoreV1().ConfigMaps(namespace).Get(ctx, configMapName, k8smetav1.GetOptions{})
+		})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			err = fmt.Errorf("%w: %w", ErrTemplateNotFound, err)
+		}
+
+		return nil, fmt.Errorf("failed to get config map %q in namespace %q: %w", configMapName, namespace, err)
+	}
+
+	manifest, found := configMap.Data[key]
+	if !found {
+		return nil, fmt.Errorf("%w: config map %q in namespace %q has no %q key",
+			ErrTemplateNotFound, configMapName, namespace, key)
+	}
+
+	virtualMachine, err := decodeTemplate([]byte(manifest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode virtual machine template %q in namespace %q: %w", name, namespace, err)
+	}
+
+	return virtualMachine, nil
+}
+
+type clusterTemplateSource struct {
+	rc *KubevirtRunner
+}
+
+func (s clusterTemplateSource) LoadTemplate(ctx context.Context, name, _ string) (*v1.VirtualMachine, error) {
+	object, err := withRetry(ctx, s.rc.retryPolicy, "get virtual machine cluster template",
+		func() (*unstructured.Unstructured, error) {
+			return s.rc.virtClient.DynamicClient().Resource(VirtualMachineClusterTemplateResource).Get(
+				ctx, name, k8smetav1.GetOptions{})
+		})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			err = fmt.Errorf("%w: %w", ErrTemplateNotFound, err)
+		}
+
+		return nil, fmt.Errorf("failed to get virtual machine cluster template %q: %w", name, err)
+	}
+
+	return virtualMachineFromClusterTemplate(object)
+}
+
+func virtualMachineFromClusterTemplate(object *unstructured.Unstructured) (*v1.VirtualMachine, error) {
+	var clusterTemplate struct {
+		Spec struct {
+			VirtualMachineTemplate *v1.VirtualMachine `json:"virtualMachineTemplate"`
+		} `json:"spec"`
+	}
+
+	data, err := object.MarshalJSON()
+	if err == nil {
+		err = json.Unmarshal(data, &clusterTemplate)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: virtual machine cluster template %q: %w",
+			ErrInvalidTemplateSource, object.GetName(), err)
+	}
+
+	virtualMachine := clusterTemplate.Spec.VirtualMachineTemplate
+	if virtualMachine == nil || virtualMachine.Spec.Template == nil {
+		return nil, fmt.Errorf("%w: virtual machine cluster template %q has no virtual machine instance template",
+			ErrInvalidTemplateSource, object.GetName())
+	}
+
+	virtualMachine.Name = object.GetName()
+
+	return virtualMachine, nil
+}
+
+// decodeTemplate parses a YAML or JSON VirtualMachine manifest.
+func decodeTemplate(data []byte) (*v1.VirtualMachine, error) {
+	var virtualMachine v1.VirtualMachine
+
+	err := yaml.Unmarshal(data, &virtualMachine)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTemplateSource, err)
+	}
+
+	if virtualMachine.Kind != "" && virtualMachine.Kind != v1.VirtualMachineGroupVersionKind.Kind {
+		return nil, fmt.Errorf("%w: manifest of kind %q", ErrInvalidTemplateSource, virtualMachine.Kind)
+	}
+
+	if virtualMachine.Spec.Template == nil {
+		return nil, fmt.Errorf("%w: manifest has no virtual machine instance template", ErrInvalidTemplateSource)
+	}
+
+	return &virtualMachine, nil
+}
@@ -0,0 +1,217 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	runner "github.com/electrocucaracha/kubevirt-actions-runner/internal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	k8sv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime" //nolint:depguard // required by fake reactor signature
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing" //nolint:depguard // required by fake reactor signature
+	cdifake "kubevirt.io/client-go/containerizeddataimporter/fake"
+	"kubevirt.io/client-go/kubecli"
+)
+
+var _ = Describe("Template sources", func() {
+	const (
+		runnerName = "runner-sourced"
+		manifest   = `apiVersion: kubevirt.io/v1
+kind: VirtualMachine
+metadata:
+  name: windows-2022
+spec:
+  template:
+    spec:
+      domain:
+        cpu:
+          cores: 8
+`
+	)
+
+	var (
+		renderer      runner.Renderer
+		k8sClientset  *k8sfake.Clientset
+		dynamicClient *dynamicfake.FakeDynamicClient
+	)
+
+	render := func(source runner.TemplateSourceKind, template string) (*runner.RenderedResources, error) {
+		return renderer.RenderResources(context.TODO(), runner.RenderOptions{
+			VMTemplate: template,
+			RunnerName: runnerName,
+			Template:   runner.TemplateOptions{Source: source},
+		})
+	}
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		virtClient := kubecli.NewMockKubevirtClient(mockCtrl)
+		k8sClientset = k8sfake.NewSimpleClientset()
+		dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				runner.VirtualMachineClusterTemplateResource: "VirtualMachineClusterTemplateList",
+			})
+
+		virtClient.EXPECT().CdiClient().Return(cdifake.NewSimpleClientset()).AnyTimes()
+		virtClient.EXPECT().CoreV1().Return(k8sClientset.CoreV1()).AnyTimes()
+		virtClient.EXPECT().DynamicClient().Return(dynamicClient).AnyTimes()
+
+		renderer = runner.NewRunner(k8sv1.NamespaceDefault, virtClient, time.Minute,
+			runner.WithRetryPolicy(runner.RetryPolicy{
+				MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond,
+			}))
+	})
+
+	Context("with a file", func() {
+		It("loads the template from the manifest", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "windows-2022.yaml")
+			Expect(os.WriteFile(path, []byte(manifest), 0o600)).To(Succeed())
+
+			rendered, err := render(runner.TemplateSourceFile, path)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rendered.VirtualMachineInstance.Spec.Domain.CPU.Cores).To(Equal(uint32(8)))
+		})
+
+		It("fails when the file doesn't exist", func() {
+			_, err := render(runner.TemplateSourceFile, filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+
+			Expect(err).To(MatchError(runner.ErrTemplateNotFound))
+		})
+
+		It("fails when the manifest isn't a virtual machine", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "pod.yaml")
+			Expect(os.WriteFile(path, []byte("apiVersion: v1\nkind: Pod\n"), 0o600)).To(Succeed())
+
+			_, err := render(runner.TemplateSourceFile, path)
+
+			Expect(err).To(MatchError(runner.ErrInvalidTemplateSource))
+		})
+	})
+
+	Context("with a config map", func() {
+		BeforeEach(func() {
+			Expect(k8sClientset.Tracker().Add(&k8sv1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "runner-templates", Namespace: k8sv1.NamespaceDefault},
+				Data:       map[string]string{"windows.yaml": manifest},
+			})).To(Succeed())
+		})
+
+		It("loads the template from the key", func() {
+			rendered, err := render(runner.TemplateSourceConfigMap, "runner-templates/windows.yaml")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rendered.VirtualMachineInstance.Spec.Domain.CPU.Cores).To(Equal(uint32(8)))
+		})
+
+		It("retries the config map lookup after transient errors", func() {
+			attempts := 0
+			k8sClientset.PrependReactor("get", "configmaps", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+				attempts++
+				if attempts > 1 {
+					return false, nil, nil
+				}
+
+				return true, nil, k8serrors.NewInternalError(errSimulatedTransientGetFailure)
+			})
+
+			_, err := render(runner.TemplateSourceConfigMap, "runner-templates/windows.yaml")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attempts).To(Equal(2))
+		})
+
+		It("fails when the key doesn't exist", func() {
+			_, err := render(runner.TemplateSourceConfigMap, "runner-templates/linux.yaml")
+
+			Expect(err).To(MatchError(runner.ErrTemplateNotFound))
+		})
+
+		It("fails when the reference has no key", func() {
+			_, err := render(runner.TemplateSourceConfigMap, "runner-templates")
+
+			Expect(err).To(MatchError(runner.ErrInvalidTemplateSource))
+		})
+	})
+
+	Context("with a cluster template", func() {
+		clusterTemplate := func(name string, virtualMachineTemplate map[string]any) *unstructured.Unstructured {
+			object := &unstructured.Unstructured{Object: map[string]any{
+				"spec": map[string]any{"virtualMachineTemplate": virtualMachineTemplate},
+			}}
+			object.SetAPIVersion(runner.VirtualMachineClusterTemplateResource.GroupVersion().String())
+			object.SetKind("VirtualMachineClusterTemplate")
+			object.SetName(name)
+
+			return object
+		}
+
+		It("loads the virtual machine template of the cluster template", func() {
+			Expect(dynamicClient.Tracker().Add(clusterTemplate("windows-2022", map[string]any{
+				"metadata": map[string]any{"labels": map[string]any{"os": "windows"}},
+				"spec": map[string]any{
+					"template": map[string]any{
+						"spec": map[string]any{"domain": map[string]any{"cpu": map[string]any{"cores": int64(8)}}},
+					},
+				},
+			}))).To(Succeed())
+
+			rendered, err := render(runner.TemplateSourceClusterTemplate, "windows-2022")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rendered.VirtualMachineInstance.Name).To(Equal(runnerName))
+			Expect(rendered.VirtualMachineInstance.Spec.Domain.CPU.Cores).To(Equal(uint32(8)))
+			Expect(rendered.VirtualMachineInstance.Labels).To(HaveKeyWithValue(runner.TemplateLabel, "windows-2022"))
+		})
+
+		It("fails when the cluster template doesn't exist", func() {
+			_, err := render(runner.TemplateSourceClusterTemplate, "missing-template")
+
+			Expect(err).To(MatchError(runner.ErrTemplateNotFound))
+		})
+
+		It("fails when the cluster template has no virtual machine instance template", func() {
+			Expect(dynamicClient.Tracker().Add(clusterTemplate("empty-template", map[string]any{
+				"spec": map[string]any{"runStrategy": "Halted"},
+			}))).To(Succeed())
+
+			_, err := render(runner.TemplateSourceClusterTemplate, "empty-template")
+
+			Expect(err).To(MatchError(runner.ErrInvalidTemplateSource))
+		})
+	})
+
+	It("rejects an unknown template source", func() {
+		_, err := runner.ParseTemplateSource("git")
+
+		Expect(err).To(MatchError(runner.ErrInvalidTemplateSource))
+	})
+})
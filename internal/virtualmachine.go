@@ -0,0 +1,222 @@
+/* jscpd:ignore-start */
+/*
+Copyright © 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/* jscpd:ignore-end */
+
+package runner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/electrocucaracha/kubevirt-actions-runner/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	k8scorev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "kubevirt.io/api/core/v1"
+)
+
+// createVirtualMachineResources creates the runner as a VirtualMachine with
+// the Once run strategy. KubeVirt creates the VMI and the DataVolumes from
+// the VirtualMachine, so only the runner info Secret is created here.
+func (rc *KubevirtRunner) createVirtualMachineResources(
+	ctx context.Context,
+	tracer trace.Tracer,
+	span trace.Span,
+	vmTemplate, vmTemplateNamespace, runnerName, jitConfig string,
+	templateOptions TemplateOptions,
+	overrides ResourceOverrides,
+	management managementMetadata,
+) error {
+	virtualMachine, secret, err := rc.getVirtualMachineResources(
+		ctx,
+		vmTemplate,
+		vmTemplateNamespace,
+		runnerName,
+		jitConfig,
+		templateOptions,
+		overrides,
+		management,
+	)
+	if err != nil {
+		span.RecordError(err)
+
+		return err
+	}
+
+	_, spanCreateVM := tracer.Start(ctx, "CreateVM",
+		trace.WithAttributes(
+			attribute.String("vmName", virtualMachine.Name),
+		),
+	)
+	defer spanCreateVM.End()
+
+	vm, err := rc.createVirtualMachine(ctx, virtualMachine, span, spanCreateVM)
+	if err != nil {
+		return err
+	}
+
+	owner := ownerReference(v1.VirtualMachineGroupVersionKind.Kind, vm.Name, vm.UID)
+
+	err = rc.createSecret(ctx, tracer, secret, owner, span)
+	if err != nil {
+		return err
+	}
+
+	// The VMI created by the VirtualMachine controller shares its name. The
+	// DataVolumes are recorded so the cleanup can await them and preserving
+	// the runner keeps them.
+	dataVolumeNames := make([]string, 0, len(virtualMachine.Spec.DataVolumeTemplates))
+	for _, dvt := range virtualMachine.Spec.DataVolumeTemplates {
+		dataVolumeNames = append(dataVolumeNames, dvt.Name)
+	}
+
+	NewAppContext(virtualMachine.Name, dataVolumeNames, secret.Name)
+
+	return nil
+}
+
+func (rc *KubevirtRunner) createVirtualMachine(
+	ctx context.Context,
+	vm *v1.VirtualMachine,
+	span, spanCreateVM trace.Span,
+) (*v1.VirtualMachine, error) {
+	log := utils.GetLogger()
+	log.Printf("Creating %s Virtual Machine\n", vm.Name)
+
+	vm.OwnerReferences = rc.podOwnerReference()
+
+	vmInterface := rc.virtClient.VirtualMachine(rc.namespace)
+
+	createdVM, err := createWithRetry(ctx, rc.retryPolicy, "create virtual machine", vm,
+		func() (*v1.VirtualMachine, error) {
+			return vmInterface.Create(ctx, vm, k8smetav1.CreateOptions{})
+		},
+		func() (*v1.VirtualMachine, error) {
+			return vmInterface.Get(ctx, vm.Name, k8smetav1.GetOptions{})
+		})
+	if err != nil {
+		if k8serrors.IsAlreadyExists(err) {
+			log.Printf("Virtual Machine %s already exists\n", vm.Name)
+			span.SetAttributes(attribute.String("vmName", vm.Name))
+
+			existingVM, getErr := vmInterface.Get(ctx, vm.Name, k8smetav1.GetOptions{})
+			if getErr != nil {
+				spanCreateVM.RecordError(getErr)
+				span.RecordError(getErr)
+
+				return nil, fmt.Errorf("failed to get existing runner virtual machine: %w", getErr)
+			}
+
+			return existingVM, nil
+		}
+
+		log.Printf("Failed to create runner virtual machine %s: %v\n", vm.Name, err)
+		rc.events.Warningf(EventReasonFailedCreate, "Failed to create virtual machine %s: %v", vm.Name, err)
+		spanCreateVM.RecordError(err)
+		span.RecordError(err)
+
+		return nil, fmt.Errorf("failed to create runner virtual machine: %w", err)
+	}
+
+	rc.events.Normalf(EventReasonCreated, "Created virtual machine %s", vm.Name)
+
+	return createdVM, nil
+}
+
+func (rc *KubevirtRunner) getVirtualMachineResources(
+	ctx context.Context,
+	vmTemplate, vmTemplateNamespace, runnerName, jitConfig string,
+	templateOptions TemplateOptions,
+	overrides ResourceOverrides,
+	management managementMetadata,
+) (*v1.VirtualMachine, *k8scorev1.Secret, error) {
+	template, err := rc.getTemplate(ctx, templateOptions.Source, vmTemplate, vmTemplateNamespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Without CPU and memory overrides the instancetype is left for KubeVirt
+	// to apply, so the VirtualMachine keeps referencing it.
+	if overrides.CPUCores != 0 || overrides.Memory != nil {
+		template, err = rc.expandTemplate(template)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err = rc.restoreDiskSources(ctx, template, vmTemplateNamespace, templateOptions.DiskRestoreStrategy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	secret, err := newRunnerInfoSecret(runnerName, jitConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	management.apply(&secret.ObjectMeta)
+
+	runStrategy := v1.RunStrategyOnce
+
+	virtualMachine := &v1.VirtualMachine{
+		ObjectMeta: k8smetav1.ObjectMeta{
+			Name:      runnerName,
+			Namespace: rc.namespace,
+		},
+		Spec: *template.Spec.DeepCopy(),
+	}
+	virtualMachine.Spec.Running = nil
+	management.apply(&virtualMachine.ObjectMeta)
+	management.apply(&virtualMachine.Spec.Template.ObjectMeta)
+	virtualMachine.Spec.RunStrategy = &runStrategy
+	overrides.applyToSpec(&virtualMachine.Spec.Template.Spec)
+	rc.applyShutdownGracePeriod(&virtualMachine.Spec.Template.Spec)
+
+	overrides.applyToBootDataVolume(&virtualMachine.Spec)
+
+	volumes := virtualMachine.Spec.Template.Spec.Volumes
+
+	for i := range virtualMachine.Spec.DataVolumeTemplates {
+		dvt := &virtualMachine.Spec.DataVolumeTemplates[i]
+		dataVolumeName := fmt.Sprintf("%s-%s", dvt.Name, runnerName)
+
+		for j := range volumes {
+			renameDataVolumeReference(&volumes[j], dvt.Name, dataVolumeName)
+		}
+
+		dvt.Name = dataVolumeName
+		management.apply(&dvt.ObjectMeta)
+	}
+
+	virtualMachine.Spec.Template.Spec.Volumes = append(volumes, generateRunnerInfoVolume(secret.Name))
+
+	return virtualMachine, secret, nil
+}
+
+// renameDataVolumeReference points a volume that consumes a
+// DataVolumeTemplate to the per-runner name of that template.
+func renameDataVolumeReference(volume *v1.Volume, oldName, newName string) {
+	if volume.DataVolume != nil && volume.DataVolume.Name == oldName {
+		volume.DataVolume.Name = newName
+	}
+
+	if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == oldName {
+		volume.PersistentVolumeClaim.ClaimName = newName
+	}
+}